@@ -0,0 +1,413 @@
+package evaluator
+
+import (
+	"monkey/object"
+	"sort"
+)
+
+// The array builtins call back into Monkey functions through
+// applyFunction, so they are registered in init to avoid an initialization
+// cycle with the builtins table.
+func init() {
+	for name, fn := range map[string]object.BuiltinFunction{
+		"map":      arrayMap,
+		"filter":   arrayFilter,
+		"reduce":   arrayReduce,
+		"each":     arrayEach,
+		"find":     arrayFind,
+		"any":      arrayAny,
+		"all":      arrayAll,
+		"zip":      arrayZip,
+		"flatten":  arrayFlatten,
+		"sort":     arraySort,
+		"reverse":  arrayReverse,
+		"index_of": indexOf,
+		"concat":   arrayConcat,
+		"unique":   arrayUnique,
+	} {
+		builtins[name] = &object.Builtin{Name: name, Fn: fn}
+	}
+}
+
+// maxArrayLength caps the arrays built from ranges, so that a huge range is
+// reported as an error instead of exhausting memory.
+const maxArrayLength = 1 << 24
+
+// rangeToArray materialises r for the builtin name.
+func rangeToArray(name string, r *object.Range) (*object.Array, *object.Error) {
+	if r.Len() > maxArrayLength {
+		return nil, newError("range %s is too long for `%s`, max %d elements", r.Inspect(), name, maxArrayLength)
+	}
+	return r.ToArray(), nil
+}
+
+// arrayArgument returns the elements of the array argument of the builtin
+// name. A tuple or a range is accepted too; a range is materialised, so one
+// longer than maxArrayLength is an error.
+func arrayArgument(name string, arg object.Object) ([]object.Object, *object.Error) {
+	switch arg := arg.(type) {
+	case *object.Array:
+		return arg.Elements, nil
+	case *object.Tuple:
+		return arg.Elements, nil
+	case *object.Range:
+		array, err := rangeToArray(name, arg)
+		if err != nil {
+			return nil, err
+		}
+		return array.Elements, nil
+	}
+	return nil, newError("argument to `%s` must be ARRAY, got %s", name, arg.Type())
+}
+
+func arrayMap(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	elements, err := arrayArgument("map", args[0])
+	if err != nil {
+		return err
+	}
+
+	result := make([]object.Object, 0, len(elements))
+	for _, el := range elements {
+		mapped := applyFunction(args[1], []object.Object{el})
+		if isError(mapped) {
+			return mapped
+		}
+		result = append(result, mapped)
+	}
+	return &object.Array{Elements: result}
+}
+
+func arrayFilter(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	elements, err := arrayArgument("filter", args[0])
+	if err != nil {
+		return err
+	}
+
+	result := make([]object.Object, 0)
+	for _, el := range elements {
+		keep := applyFunction(args[1], []object.Object{el})
+		if isError(keep) {
+			return keep
+		}
+		if isTruthy(keep) {
+			result = append(result, el)
+		}
+	}
+	return &object.Array{Elements: result}
+}
+
+// arrayReduce folds the array with f(acc, x). Without an initial value the
+// first element is used.
+func arrayReduce(args ...object.Object) object.Object {
+	if len(args) != 2 && len(args) != 3 {
+		return newError("wrong number of arguments. got=%d, want=2 or 3", len(args))
+	}
+	elements, err := arrayArgument("reduce", args[0])
+	if err != nil {
+		return err
+	}
+
+	var acc object.Object
+	if len(args) == 3 {
+		acc = args[2]
+	} else {
+		if len(elements) == 0 {
+			return newError("reduce of empty array with no initial value")
+		}
+		acc, elements = elements[0], elements[1:]
+	}
+
+	for _, el := range elements {
+		acc = applyFunction(args[1], []object.Object{acc, el})
+		if isError(acc) {
+			return acc
+		}
+	}
+	return acc
+}
+
+func arrayEach(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	elements, err := arrayArgument("each", args[0])
+	if err != nil {
+		return err
+	}
+
+	for _, el := range elements {
+		if result := applyFunction(args[1], []object.Object{el}); isError(result) {
+			return result
+		}
+	}
+	return NULL
+}
+
+// arrayFind returns the first element pred accepts, or null.
+func arrayFind(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	elements, err := arrayArgument("find", args[0])
+	if err != nil {
+		return err
+	}
+
+	for _, el := range elements {
+		found := applyFunction(args[1], []object.Object{el})
+		if isError(found) {
+			return found
+		}
+		if isTruthy(found) {
+			return el
+		}
+	}
+	return NULL
+}
+
+func arrayAny(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	elements, err := arrayArgument("any", args[0])
+	if err != nil {
+		return err
+	}
+
+	for _, el := range elements {
+		result := applyFunction(args[1], []object.Object{el})
+		if isError(result) {
+			return result
+		}
+		if isTruthy(result) {
+			return TRUE
+		}
+	}
+	return FALSE
+}
+
+func arrayAll(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	elements, err := arrayArgument("all", args[0])
+	if err != nil {
+		return err
+	}
+
+	for _, el := range elements {
+		result := applyFunction(args[1], []object.Object{el})
+		if isError(result) {
+			return result
+		}
+		if !isTruthy(result) {
+			return FALSE
+		}
+	}
+	return TRUE
+}
+
+// arrayZip pairs up the elements of its arguments, stopping at the end of
+// the shortest one.
+func arrayZip(args ...object.Object) object.Object {
+	if len(args) < 2 {
+		return newError("wrong number of arguments. got=%d, want at least 2", len(args))
+	}
+
+	arrays := make([][]object.Object, len(args))
+	length := -1
+	for i, arg := range args {
+		elements, err := arrayArgument("zip", arg)
+		if err != nil {
+			return err
+		}
+		arrays[i] = elements
+		if length < 0 || len(elements) < length {
+			length = len(elements)
+		}
+	}
+
+	result := make([]object.Object, length)
+	for i := range result {
+		tuple := make([]object.Object, len(arrays))
+		for j, elements := range arrays {
+			tuple[j] = elements[i]
+		}
+		result[i] = &object.Array{Elements: tuple}
+	}
+	return &object.Array{Elements: result}
+}
+
+// arrayFlatten splices nested arrays into the result, one level deep.
+func arrayFlatten(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	elements, err := arrayArgument("flatten", args[0])
+	if err != nil {
+		return err
+	}
+
+	result := make([]object.Object, 0, len(elements))
+	for _, el := range elements {
+		if nested, ok := el.(*object.Array); ok {
+			result = append(result, nested.Elements...)
+		} else {
+			result = append(result, el)
+		}
+	}
+	return &object.Array{Elements: result}
+}
+
+// arraySort returns a sorted copy of the array. Elements are ordered with
+// object.Compare unless a comparator is given; cmp(a, b) returns a negative
+// integer, or true, when a goes before b.
+func arraySort(args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	elements, err := arrayArgument("sort", args[0])
+	if err != nil {
+		return err
+	}
+
+	var sortErr object.Object
+	less := func(a, b object.Object) bool {
+		if sortErr != nil {
+			return false
+		}
+
+		if len(args) == 1 {
+			result, ok := object.Compare(a, b)
+			if !ok {
+				sortErr = newError("cannot compare %s and %s", a.Type(), b.Type())
+			}
+			return result < 0
+		}
+
+		switch result := applyFunction(args[1], []object.Object{a, b}).(type) {
+		case *object.Integer:
+			return result.Value < 0
+		case *object.Boolean:
+			return result.Value
+		default:
+			if isError(result) {
+				sortErr = result
+			} else {
+				sortErr = newError("comparator must return INTEGER or BOOLEAN, got %s", result.Type())
+			}
+			return false
+		}
+	}
+
+	sorted := append([]object.Object{}, elements...)
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+	if sortErr != nil {
+		return sortErr
+	}
+	return &object.Array{Elements: sorted}
+}
+
+func arrayReverse(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	elements, err := arrayArgument("reverse", args[0])
+	if err != nil {
+		return err
+	}
+
+	result := make([]object.Object, len(elements))
+	for i, el := range elements {
+		result[len(elements)-1-i] = el
+	}
+	return &object.Array{Elements: result}
+}
+
+// indexOf is the index_of builtin, which searches strings as well as
+// arrays.
+func indexOf(args ...object.Object) object.Object {
+	if len(args) == 2 && args[0].Type() == object.STRING_OBJ {
+		return stringIndexOf(args...)
+	}
+	return arrayIndexOf(args...)
+}
+
+// arrayIndexOf returns the index of the first element equal to the value,
+// or -1.
+func arrayIndexOf(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	elements, err := arrayArgument("index_of", args[0])
+	if err != nil {
+		return err
+	}
+
+	for i, el := range elements {
+		if object.Equals(el, args[1]) {
+			return &object.Integer{Value: int64(i)}
+		}
+	}
+	return &object.Integer{Value: -1}
+}
+
+func arrayConcat(args ...object.Object) object.Object {
+	result := []object.Object{}
+	for _, arg := range args {
+		elements, err := arrayArgument("concat", arg)
+		if err != nil {
+			return err
+		}
+		result = append(result, elements...)
+	}
+	return &object.Array{Elements: result}
+}
+
+// arrayUnique returns the elements without duplicates, keeping the first
+// occurrence of each.
+func arrayUnique(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	elements, err := arrayArgument("unique", args[0])
+	if err != nil {
+		return err
+	}
+
+	// hashable elements are looked up in a hash; the rest are compared
+	// against each other
+	seen := object.NewHash()
+	unhashable := []object.Object{}
+	result := []object.Object{}
+
+	for _, el := range elements {
+		if object.IsHashable(el) {
+			if _, ok := seen.Get(el); ok {
+				continue
+			}
+			seen.Set(el, TRUE)
+			result = append(result, el)
+			continue
+		}
+
+		duplicate := false
+		for _, other := range unhashable {
+			if object.Equals(el, other) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			unhashable = append(unhashable, el)
+			result = append(result, el)
+		}
+	}
+	return &object.Array{Elements: result}
+}
@@ -4,6 +4,9 @@ import (
 	"monkey/lexer"
 	"monkey/object"
 	"monkey/parse"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -128,6 +131,29 @@ func TestIfElseExpressions(t *testing.T) {
 		}
 	}
 }
+func TestElseIfExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"if (false) { 10 } else if (true) { 20 } else { 30 }", 20},
+		{"if (false) { 10 } else if (false) { 20 } else { 30 }", 30},
+		{"if (true) { 10 } else if (true) { 20 } else { 30 }", 10},
+		{"if (false) { 10 } else if (false) { 20 }", nil},
+		{"if (1 > 2) { 1 } else if (1 > 3) { 2 } else if (1 < 4) { 3 } else { 4 }", 3},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
 
 func TestReturnStatements(t *testing.T) {
 	tests := []struct {
@@ -233,6 +259,8 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`len("")`, 0},
 		{`len("four")`, 4},
 		{`len("hello world")`, 11},
+		{`len([])`, 0},
+		{`len([1, 2, 3])`, 3},
 		{`len(1)`, "argument to `len` not supported, got INTEGER"},
 		{`len("one", "two")`, "wrong number of arguments. got=2, want=1"},
 	}
@@ -376,7 +404,9 @@ func TestArrayIndexExpression(t *testing.T) {
 		{"let myArray = [1, 2, 3]; myArray[0] + myArray[1] + myArray[2];", 6},
 		{"let myArray = [1, 2, 3]; let i = myArray[0]; myArray[i]",2},
 		{"[1, 2, 3][3];", nil},
-		{"[1, 2, 3][-1];", nil},
+		{"[1, 2, 3][-1];", 3},
+		{"[1, 2, 3][-3];", 1},
+		{"[1, 2, 3][-4];", nil},
 	}
 
 	for _, tt := range tests {
@@ -407,26 +437,28 @@ func TestHashLiterals(t *testing.T) {
 		t.Fatalf("Eval didn't return Hash. got=%T (%+v)", evaluated, evaluated)
 	}
 
-	expected := map[object.HashKey]int64{
-		(&object.String{Value: "one"}).HashKey():   1,
-		(&object.String{Value: "two"}).HashKey():   2,
-		(&object.String{Value: "three"}).HashKey(): 3,
-		(&object.Integer{Value: 4}).HashKey():      4,
-		TRUE.HashKey():                             5,
-		FALSE.HashKey():                            6,
+	expected := []struct {
+		key   object.Object
+		value int64
+	}{
+		{&object.String{Value: "one"}, 1},
+		{&object.String{Value: "two"}, 2},
+		{&object.String{Value: "three"}, 3},
+		{&object.Integer{Value: 4}, 4},
+		{TRUE, 5},
+		{FALSE, 6},
 	}
 
-	if len(result.Pairs) != len(expected) {
-		t.Fatalf("Hash has wrong num of pairs. got=%d", len(result.Pairs))
+	if result.Len() != len(expected) {
+		t.Fatalf("Hash has wrong num of pairs. got=%d", result.Len())
 	}
 
-	for expectedKey, expectedValue := range expected {
-		pair, ok := result.Pairs[expectedKey]
-		if !ok {
-			t.Errorf("no pair for given key in Pairs")
+	for i, pair := range result.Entries() {
+		if !object.Equals(pair.Key, expected[i].key) {
+			t.Errorf("pair %d has wrong key. want=%s, got=%s", i, expected[i].key.Inspect(), pair.Key.Inspect())
 		}
 
-		testIntegerObject(t, pair.Value, expectedValue)
+		testIntegerObject(t, pair.Value, expected[i].value)
 	}
 }
 
@@ -475,6 +507,1814 @@ func TestHashIndexExpressions(t *testing.T) {
 		}
 	}
 }
+func TestAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let a = 5; a = 10; a;", 10},
+		{"let a = 5; a += 10; a;", 15},
+		{"let a = 5; a -= 10; a;", -5},
+		{"let a = 5; a *= 2; a;", 10},
+		{"let a = 5; a /= 5; a;", 1},
+		{"let a = 1; let b = 2; a = b = 3; a + b;", 6},
+		{"let a = 1; let f = fn() { a = 2; }; f(); a;", 2},
+		{"b = 1;", "identifier not found: b"},
+		{"b += 1;", "identifier not found: b"},
+		{`let a = 1; a += "x";`, "type mismatch: INTEGER + STRING"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestWhileStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let i = 0; while (i < 10) { i += 1; } i;", 10},
+		{"let i = 0; while (false) { i += 1; } i;", 0},
+		{"let i = 0; while (true) { i += 1; if (i > 4) { break; } } i;", 5},
+		{
+			`let i = 0; let sum = 0;
+			while (i < 10) {
+				i += 1;
+				if (i / 2 * 2 == i) { continue; }
+				sum += i;
+			}
+			sum;`,
+			25,
+		},
+		{"let f = fn() { while (true) { return 7; } }; f();", 7},
+		{"while (true) { 1 + true; }", "type mismatch: INTEGER + BOOLEAN"},
+		{"while (x) { 1 }", "identifier not found: x"},
+		{"let i = 0; while (i < 3) { let x = i; i += 1; } x;", "identifier not found: x"},
+		{
+			`let fs = []; let i = 0;
+			while (i < 3) { let j = i; fs = push(fs, fn() { j }); i += 1; }
+			fs[0]();`,
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestForStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let sum = 0; for (let i = 0; i < 5; i += 1) { sum += i; } sum;", 10},
+		{"let i = 0; for (; i < 5;) { i += 1; } i;", 5},
+		{"let i = 0; for (i = 3; i < 5; i += 1) {} i;", 5},
+		{"for (let i = 0; i < 3; i += 1) { let y = i; } y;", "identifier not found: y"},
+		{"let n = 0; for (let i = 0; i < 3; i += 1) { let n = i; } n;", 0},
+		{
+			`let fs = [];
+			for (let i = 0; i < 3; i += 1) { push(fs, fn() { i }); }
+			fs[0]() * 100 + fs[1]() * 10 + fs[2]();`,
+			12,
+		},
+		{"let n = 0; for (let i = 0; i < 10; i += 1) { i += 1; n += 1; } n;", 5},
+		{"let n = 0; for (;;) { n += 1; if (n == 3) { break; } } n;", 3},
+		{
+			`let sum = 0;
+			for (let i = 0; i < 10; i += 1) {
+				if (i < 5) { continue; }
+				sum += i;
+			}
+			sum;`,
+			35,
+		},
+		{
+			`let count = 0;
+			for (let i = 0; i < 3; i += 1) {
+				for (let j = 0; j < 3; j += 1) {
+					if (j == 2) { break; }
+					count += 1;
+				}
+			}
+			count;`,
+			6,
+		},
+		{
+			`let find = fn(n) {
+				for (let i = 0; true; i += 1) {
+					if (i * i > n) { return i; }
+				}
+			};
+			find(50);`,
+			8,
+		},
+		{"for (let i = 0; i < 1; i += 1) {} i;", "identifier not found: i"},
+		{"let big = 0; for (let i = 0; i < 100000; i += 1) { big += 1; } big;", 100000},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestLoopControlOutsideLoop(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"break;", "break outside loop"},
+		{"continue;", "continue outside loop"},
+		{"if (true) { break; }", "break outside loop"},
+		{"let f = fn() { break; }; f();", "break outside loop"},
+		{"while (true) { let f = fn() { continue; }; f(); }", "continue outside loop"},
+	}
+
+	for _, tt := range tests {
+		testErrorObject(t, testEval(tt.input), tt.expected)
+	}
+}
+func TestForInStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let sum = 0; for (x in [1, 2, 3]) { sum += x; } sum;", 6},
+		{"let sum = 0; for (i, x in [10, 20, 30]) { sum += i * x; } sum;", 80},
+		{"let sum = 0; for (x in []) { sum += 1; } sum;", 0},
+		{`let s = ""; for (c in "abc") { s = c + s; } s;`, "cba"},
+		{`let s = ""; for (i, c in "ab") { s += c; s += c; } s;`, "aabb"},
+		{`let s = ""; for (k in {"b": 2, "a": 1, "c": 3}) { s += k; } s;`, "bac"},
+		{`let sum = 0; for (k, v in {"b": 2, "a": 1}) { sum += v; } sum;`, 3},
+		{`let s = ""; for (k, v in {"x": "1", "y": "2"}) { s += k + v; } s;`, "x1y2"},
+		{"let sum = 0; for (x in [1, 2, 3, 4]) { if (x == 3) { break; } sum += x; } sum;", 3},
+		{"let sum = 0; for (x in [1, 2, 3, 4]) { if (x == 3) { continue; } sum += x; } sum;", 7},
+		{"let f = fn(xs) { for (x in xs) { if (x > 1) { return x; } } }; f([1, 5, 7]);", 5},
+		{
+			`let fs = [];
+			for (x in [1, 2, 3]) { push(fs, fn() { x }); }
+			fs[0]() + fs[2]();`,
+			4,
+		},
+		{
+			`let counter = fn(n) {
+				let i = 0;
+				fn() { if (i < n) { i += 1; i } }
+			};
+			let sum = 0;
+			for (x in counter(4)) { sum += x; }
+			sum;`,
+			10,
+		},
+		{"let n = 0; let g = fn() {}; for (x in g) { put(x); n += 1; } n;", 0},
+		{
+			`let words = ["a", "b"];
+			let next = fn() { if (len(words) > 0) { let w = words[0]; words = rest(words); w } };
+			let s = "";
+			for (w in next) { s += w; }
+			s;`,
+			"ab",
+		},
+		{"for (x in 5) { x }", "not iterable: INTEGER"},
+		{"for (x in [1, true]) { x + 1 }", "type mismatch: BOOLEAN + INTEGER"},
+		{"for (x in fn() { 1 + true }) { x }", "type mismatch: INTEGER + BOOLEAN"},
+		{"for (x in [1]) {} x;", "identifier not found: x"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if str, ok := evaluated.(*object.String); ok {
+				if str.Value != expected {
+					t.Errorf("String has wrong value. expected=%q, got=%q", expected, str.Value)
+				}
+				continue
+			}
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestMatchExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"match (1) { 1 => 10, 2 => 20 }", 10},
+		{"match (2) { 1 => 10, 2 => 20 }", 20},
+		{"match (3) { 1 => 10, 2 => 20 }", nil},
+		{"match (3) { 1, 2 => 10, 3, 4 => 20 }", 20},
+		{"match (5) { 1 => 10, _ => 99 }", 99},
+		{"match (5) { n => n * 2 }", 10},
+		{`match ("b") { "a" => 1, "b" => 2 }`, 2},
+		{"match (true) { false => 1, true => 2 }", 2},
+		{"match (-1) { -1 => 1, _ => 2 }", 1},
+		{"match (5) { (5) => 1, _ => 2 }", 1},
+		{"match ((1, 2)) { (1, 2) => 1, _ => 2 }", 1},
+		{`match ((1, 2)) { [a, b] => a + b, _ => "no" }`, 3},
+		{`match ((1, 2)) { (a, b) => a + b, _ => "no" }`, 3},
+		{`match ((1, (2, 3))) { (1, (a, b)) => a * b, _ => 0 }`, 6},
+		{`match ([1, 2]) { (a, b) => 0, [a, b] => a + b }`, 3},
+		{`match ((1, 2, 3)) { (a, b) => 0, _ => 9 }`, 9},
+		{"match ([1, 2]) { [1] => 1, [1, x] => x + 10, _ => 0 }", 12},
+		{"match ([1, [2, 3]]) { [a, [b, c]] => a + b + c }", 6},
+		{"match ([1, 2]) { [_, _, _] => 3, [_, _] => 2 }", 2},
+		{`match ({"kind": "circle", "r": 2}) { {"kind": "square"} => 1, {"kind": "circle", "r": r} => r * 3 }`, 6},
+		{`match ({"a": 1}) { {"b": _} => 1, _ => 2 }`, 2},
+		{"match (5) { n if n < 3 => 1, n if n < 10 => 2, _ => 3 }", 2},
+		{"match (15) { n if n < 3 => 1, n if n < 10 => 2, _ => 3 }", 3},
+		{"let x = 1; match (7) { x => x }; x;", 1},
+		{"let limit = 3; match (2) { n if n < limit => { let d = n * 2; d + 1 } _ => 0 }", 5},
+		{"let f = fn(x) { match (x) { 0 => { return 100; } _ => 1 }; 50 }; f(0);", 100},
+		{"match (1 + true) { _ => 1 }", "type mismatch: INTEGER + BOOLEAN"},
+		{"match (1) { n if n + true => 1 }", "type mismatch: INTEGER + BOOLEAN"},
+		{"match (1) { _ => 1 + true }", "type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestDestructuring(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let [a, b] = [1, 2]; a + b;", 3},
+		{"let [a, [b, c]] = [1, [2, 3]]; a + b + c;", 6},
+		{"let [a, ...rest] = [1, 2, 3]; a + len(rest);", 3},
+		{"let [a, ...rest] = [1, 2, 3]; rest[1];", 3},
+		{"let [a, ...rest] = [1]; len(rest);", 0},
+		{"let [] = []; 1;", 1},
+		{`let {name, age} = {"name": "x", "age": 30}; age;`, 30},
+		{`let {age: years} = {"age": 30}; years;`, 30},
+		{`let {pos: [x, y]} = {"pos": [3, 4]}; x * y;`, 12},
+		{`let [{v: a}, {v: b}] = [{"v": 1}, {"v": 2}]; a + b;`, 3},
+		{"let add = fn([a, b]) { a + b }; add([2, 5]);", 7},
+		{`let area = fn({w, h}) { w * h }; area({"w": 2, "h": 3});`, 6},
+		{"let f = fn(x, [y, ...ys]) { x + y + len(ys) }; f(1, [2, 3, 4]);", 5},
+		{"let [a, b] = {};", "cannot destructure HASH with array pattern [a, b]"},
+		{`let {name} = [1];`, "cannot destructure ARRAY with hash pattern {name}"},
+		{"let [a, b] = 1;", "cannot destructure INTEGER with array pattern [a, b]"},
+		{"let [a, b] = [1];", "array pattern [a, b] expects 2 elements, got 1"},
+		{"let [a, b] = [1, 2, 3];", "array pattern [a, b] expects 2 elements, got 3"},
+		{"let [a, b, ...c] = [1];", "array pattern [a, b, ...c] expects at least 2 elements, got 1"},
+		{`let {name, age} = {"name": "x"};`, `hash pattern {name, age}: key "age" not found`},
+		{"let f = fn([a]) { a }; f(1);", "cannot destructure INTEGER with array pattern [a]"},
+		{"let f = fn(a, b) { a }; f(1);", "wrong number of arguments to `f`. got=1, want=2"},
+		{"fn(a, b) { a }(1);", "wrong number of arguments. got=1, want=2"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestConditionalExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"true ? 1 : 2", 1},
+		{"false ? 1 : 2", 2},
+		{"1 < 2 ? 10 : 20", 10},
+		{"let x = 5; x > 10 ? 1 : x > 3 ? 2 : 3", 2},
+		{"let abs = fn(x) { x < 0 ? -x : x }; abs(-4) + abs(4);", 8},
+		{`{}["missing"] ? 1 : 2`, 2},
+		{"true ? 1 : 1 + true", 1},
+		{"(1 + true) ? 1 : 2", "type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestNullishAndOptionalChaining(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{"a": 1}["a"] ?? 2`, 1},
+		{`{"a": 1}["b"] ?? 2`, 2},
+		{"false ?? 2", false},
+		{"0 ?? 2", 0},
+		{"[][0] ?? [][1] ?? 3", 3},
+		{"1 ?? 1 + true", 1},
+		{`{}["x"] ?? 1 + true`, "type mismatch: INTEGER + BOOLEAN"},
+		{`let p = {"name": "Ann"}; p?.name;`, "Ann"},
+		{`let p = {"name": "Ann"}; p?.["name"];`, "Ann"},
+		{`let p = {"name": "Ann"}; p?.age;`, nil},
+		{`let h = {}; h["p"]?.name;`, nil},
+		{`let h = {}; h["p"]?.["name"];`, nil},
+		{`let h = {}; h["p"]?.name ?? "anon";`, "anon"},
+		{`let h = {"p": {"q": {"r": 7}}}; h?.p?.q?.r;`, 7},
+		{`let h = {"p": {}}; h?.p?.q?.r;`, nil},
+		{"[[1, 2]]?.[0]?.[1]", 2},
+		{"let a = [1]; a?.[5] ?? 0", 0},
+		{`let h = {}; h.x?.b.c;`, nil},
+		{`let h = {}; h["x"]?.["a"]["y"];`, nil},
+		{`let h = {}; h.x?.b.c ?? "none";`, "none"},
+		{`let h = {}; h.x?.f(1)[0];`, nil},
+		{`let h = {}; h.x?.[1:].len();`, nil},
+		{`let h = {"x": {"b": {"c": 3}}}; h.x?.b.c;`, 3},
+		{`let h = {"x": {}}; h.x?.b.c;`, "no method c on NULL"},
+		{`let h = {}; h.x.b?.c;`, "no method b on NULL"},
+		{"5?.x", "no method x on INTEGER"},
+		{"5?.[0]", "index operator not supported: INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			if evaluated != nativeBoolToBooleanObject(expected) {
+				t.Errorf("object is not %t. got=%T (%+v)", expected, evaluated, evaluated)
+			}
+		case string:
+			if str, ok := evaluated.(*object.String); ok {
+				if str.Value != expected {
+					t.Errorf("String has wrong value. expected=%q, got=%q", expected, str.Value)
+				}
+				continue
+			}
+			testErrorObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestPropertyAndMethodCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"abc".upper()`, "ABC"},
+		{`"AbC".lower()`, "abc"},
+		{`"abc".len()`, 3},
+		{`let s = "hey"; s.upper().lower();`, "hey"},
+		{"[1, 2, 3].len()", 3},
+		{"[1, 2, 3].first()", 1},
+		{"[1, 2, 3].last()", 3},
+		{"[1, 2, 3].rest().len()", 2},
+		{"let a = [1]; a.push(2); a.len();", 2},
+		{"[1, 2, 3].map(fn(x) { x * 2 })[2]", 6},
+		{"[1, 2, 3, 4].filter(fn(x) { x > 2 }).len()", 2},
+		{"[1, 2, 3].map(fn(x) { x + 1 }).filter(fn(x) { x > 2 }).first()", 3},
+		{`let person = {"name": "Ann", "age": 30}; person.name;`, "Ann"},
+		{`let person = {"name": "Ann", "age": 30}; person.age + 1;`, 31},
+		{`let person = {"name": "Ann"}; person.email;`, nil},
+		{`let p = {"inner": {"v": 5}}; p.inner.v;`, 5},
+		{`let obj = {"double": fn(x) { x * 2 }}; obj.double(4);`, 8},
+		{`let upper = "abc".upper; upper();`, "ABC"},
+		{`"abc".push(1)`, "no method push on STRING"},
+		{"5.len()", "no method len on INTEGER"},
+		{"true.x", "no method x on BOOLEAN"},
+		{`"abc".upper(1)`, "wrong number of arguments. got=2, want=1"},
+		{"[1].map(fn(x) { x + true })", "type mismatch: INTEGER + BOOLEAN"},
+		{"[1].map(5)", "not a function: INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if str, ok := evaluated.(*object.String); ok {
+				if str.Value != expected {
+					t.Errorf("String has wrong value. expected=%q, got=%q", expected, str.Value)
+				}
+				continue
+			}
+			testErrorObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"[1, 2, 3, 4, 5][1:3]", []int64{2, 3}},
+		{"[1, 2, 3, 4, 5][:2]", []int64{1, 2}},
+		{"[1, 2, 3, 4, 5][3:]", []int64{4, 5}},
+		{"[1, 2, 3, 4, 5][:]", []int64{1, 2, 3, 4, 5}},
+		{"[1, 2, 3, 4, 5][-2:]", []int64{4, 5}},
+		{"[1, 2, 3, 4, 5][:-2]", []int64{1, 2, 3}},
+		{"[1, 2, 3, 4, 5][::2]", []int64{1, 3, 5}},
+		{"[1, 2, 3, 4, 5][1::2]", []int64{2, 4}},
+		{"[1, 2, 3, 4, 5][::-1]", []int64{5, 4, 3, 2, 1}},
+		{"[1, 2, 3, 4, 5][3:0:-1]", []int64{4, 3, 2}},
+		{"[1, 2, 3, 4, 5][-1:-4:-2]", []int64{5, 3}},
+		{"[1, 2, 3, 4, 5][2:-1:-1]", []int64{}},
+		{"[1, 2, 3, 4, 5][:-10:-1]", []int64{5, 4, 3, 2, 1}},
+		{"[1, 2, 3, 4, 5][3:1]", []int64{}},
+		{"[1, 2, 3, 4, 5][-100:100]", []int64{1, 2, 3, 4, 5}},
+		{"[][1:2]", []int64{}},
+		{"let a = [1, 2, 3]; let b = a[:]; push(b, 4); len(a);", 3},
+		{`"hello"[1:3]`, "el"},
+		{`"hello"[::-1]`, "olleh"},
+		{`"hello"[-3:]`, "llo"},
+		{`"hello"[10:]`, ""},
+		{`"hello"[0]`, "h"},
+		{`"hello"[-1]`, "o"},
+		{`"hello"[5]`, nil},
+		{"[1, 2][::0]", "slice step cannot be zero"},
+		{`[1, 2]["a":]`, "slice indices must be INTEGER, got STRING"},
+		{"5[1:]", "slice operator not supported: INTEGER"},
+		{`{}[1:]`, "slice operator not supported: HASH"},
+		{"let a = {}; a[\"x\"]?.[1:]", nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case []int64:
+			array, ok := evaluated.(*object.Array)
+			if !ok {
+				t.Errorf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if len(array.Elements) != len(expected) {
+				t.Errorf("wrong num of elements for %q. want=%d, got=%d",
+					tt.input, len(expected), len(array.Elements))
+				continue
+			}
+			for i, el := range expected {
+				testIntegerObject(t, array.Elements[i], el)
+			}
+		case string:
+			if str, ok := evaluated.(*object.String); ok {
+				if str.Value != expected {
+					t.Errorf("String has wrong value. expected=%q, got=%q", expected, str.Value)
+				}
+				continue
+			}
+			testErrorObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestPipeExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let double = fn(x) { x * 2 }; 5 |> double", 10},
+		{"let add = fn(x, y) { x + y }; 5 |> add(3)", 8},
+		{
+			`let even = fn(x) { x / 2 * 2 == x };
+			let double = fn(x) { x * 2 };
+			let filter = fn(xs, f) { xs.filter(f) };
+			let map = fn(xs, f) { xs.map(f) };
+			let sum = fn(xs) { let total = 0; for (x in xs) { total += x; } total };
+			[1, 2, 3, 4, 5, 6] |> filter(even) |> map(double) |> sum`,
+			24,
+		},
+		{`"abc" |> len`, 3},
+		{"[1, 2, 3] |> last", 3},
+		{"let sub = fn(a, b) { a - b }; 10 |> sub(1) |> sub(2)", 7},
+		{"5 |> fn(x) { x + 1 }", 6},
+		{`let m = {"inc": fn(x) { x + 1 }}; 1 |> m.inc()`, 2},
+		{"5 |> 3", "not a function: INTEGER"},
+		{"let f = fn(x) { x + true }; 1 |> f", "type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestFunctionStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"fn add(a, b) { a + b } add(2, 3);", 5},
+		{"let r = add(2, 3); fn add(a, b) { a + b } r;", 5},
+		{"fn fact(n) { n < 2 ? 1 : n * fact(n - 1) } fact(5);", 120},
+		{
+			`fn isEven(n) { n == 0 ? true : isOdd(n - 1) }
+			fn isOdd(n) { n == 0 ? false : isEven(n - 1) }
+			isEven(10) ? 1 : 0;`,
+			1,
+		},
+		{
+			`let outer = fn() {
+				let r = inner(4);
+				fn inner(x) { x * 10 }
+				r
+			};
+			outer();`,
+			40,
+		},
+		{"let g = f; fn f() { 1 } g == f ? 1 : 0;", 1},
+		{"f = fn() { 2 }; fn f() { 1 } f();", 2},
+		{"let f = fn() { fn g() { 1 } g() }; f(); g();", "identifier not found: g"},
+		{"fn add(a, b) { a + b } add(1);", "wrong number of arguments to `add`. got=1, want=2"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestFunctionNames(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn add(a, b) { a + b }", "add"},
+		{"let double = fn(x) { x * 2 }; double", "double"},
+		{"let inc = x => x + 1; inc", "inc"},
+		{"fn(x) { x }", ""},
+		{"x => x", ""},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		fn, ok := evaluated.(*object.Function)
+		if !ok {
+			t.Errorf("object is not Function. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if fn.Name != tt.expected {
+			t.Errorf("function has wrong name. want=%q, got=%q", tt.expected, fn.Name)
+		}
+	}
+}
+
+func TestArrowFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let double = x => x * 2; double(4);", 8},
+		{"let add = (a, b) => a + b; add(2, 3);", 5},
+		{"let seven = () => 7; seven();", 7},
+		{"let adder = x => y => x + y; adder(2)(3);", 5},
+		{"let f = (x) => { let y = x * 2; return y + 1; }; f(3);", 7},
+		{"[1, 2, 3].map(x => x * x)[2]", 9},
+		{"[1, 2, 3, 4].filter(x => x > 2).len()", 2},
+		{"5 |> (x => x + 1)", 6},
+		{"match (3) { n if (n > 2) => 1, _ => 0 }", 1},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestRangeObjects(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		length   int64
+	}{
+		{"0..5", "0..5", 5},
+		{"0..=5", "0..=5", 6},
+		{"5..0", "5..0", 0},
+		{"3..3", "3..3", 0},
+		{"3..=3", "3..=3", 1},
+		{"0..10 step 3", "0..10 step 3", 4},
+		{"0..=9 step 3", "0..=9 step 3", 4},
+		{"10..0 step -2", "10..0 step -2", 5},
+		{"10..=0 step -5", "10..=0 step -5", 3},
+		{"0..1000000000000", "0..1000000000000", 1000000000000},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		rng, ok := evaluated.(*object.Range)
+		if !ok {
+			t.Errorf("object is not Range. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if rng.Inspect() != tt.expected {
+			t.Errorf("range.Inspect() wrong. want=%q, got=%q", tt.expected, rng.Inspect())
+		}
+		if rng.Len() != tt.length {
+			t.Errorf("range.Len() wrong for %q. want=%d, got=%d", tt.input, tt.length, rng.Len())
+		}
+	}
+}
+
+func TestRangeOperations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"len(0..10)", 10},
+		{"(0..=10).len()", 11},
+		{"(0..10)[3]", 3},
+		{"(0..10 step 2)[2]", 4},
+		{"(10..0 step -1)[0]", 10},
+		{"(0..10)[-1]", 9},
+		{"(0..10)[10]", nil},
+		{"(0..1000000000000)[999999999999]", 999999999999},
+		{"len(-9223372036854775807..9223372036854775807)", 9223372036854775807},
+		{"len(9223372036854775807..=-9223372036854775807 step -1)", 9223372036854775807},
+		{"len(-9223372036854775807..9223372036854775807 step 4611686018427387904)", 4},
+		{"(-1..9223372036854775806)[-1]", 9223372036854775805},
+		{"(-9223372036854775807..9223372036854775807 step 4611686018427387904)[3]", 4611686018427387905},
+		{"9223372036854775806 in -9223372036854775807..9223372036854775807 ? 1 : 0", 1},
+		{"9223372036854775807 in -9223372036854775807..9223372036854775807 ? 1 : 0", 0},
+		{"-9223372036854775807 in 9223372036854775807..=-9223372036854775807 step -2 ? 1 : 0", 1},
+		{"3 in 0..10 ? 1 : 0", 1},
+		{"10 in 0..10 ? 1 : 0", 0},
+		{"10 in 0..=10 ? 1 : 0", 1},
+		{"5 in 0..10 step 2 ? 1 : 0", 0},
+		{"4 in 10..0 step -2 ? 1 : 0", 1},
+		{"\"a\" in 0..10", "unknown operator: STRING in RANGE"},
+		{"2.0 in 0..10 ? 1 : 0", 1},
+		{"2.5 in 0..10 ? 1 : 0", 0},
+		{"1e300 in 0..10 ? 1 : 0", 0},
+		{"let total = 0; for (i in 1..=4) { total += i }; total;", 10},
+		{"let total = 0; for (i, v in 10..0 step -5) { total += i * v }; total;", 5},
+		{"let n = 3; let a = to_array(0..n); a[2] + len(a);", 5},
+		{"(0..=6 step 3).to_array()[2]", 6},
+		{"len((5..0).to_array())", 0},
+		{"0..10 step 0", "range step cannot be zero"},
+		{"0..\"a\"", "range bounds must be INTEGER, got STRING"},
+		{"1 in 5", "unknown operator: INTEGER in INTEGER"},
+		{"to_array(1)", "argument to `to_array` not supported, got INTEGER"},
+		{"to_array(0..10000000000000000)", "range 0..10000000000000000 is too long for `to_array`, max 16777216 elements"},
+		{"tuple(0..=10000000000000000 step 2)", "range 0..=10000000000000000 step 2 is too long for `tuple`, max 16777216 elements"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestMembershipOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"a" in {"a": if (false) { 1 }}`, true},
+		{`"b" in {"a": 1}`, false},
+		{`"b" not in {"a": 1}`, true},
+		{`1 in {1: "one", true: "yes"}`, true},
+		{`true in {1: "one", true: "yes"}`, true},
+		{`false in {1: "one", true: "yes"}`, false},
+		{"2 in [1, 2, 3]", true},
+		{"4 in [1, 2, 3]", false},
+		{"4 not in [1, 2, 3]", true},
+		{"let not = fn(x) { !x }; not(4 in [1, 2, 3])", true},
+		{"let not = [1]; 1 not in not", false},
+		{`"b" in ["a", "b"]`, true},
+		{"[1, 2] in [[1, 2], [3]]", true},
+		{"[2, 1] in [[1, 2], [3]]", false},
+		{`{"a": [1]} in [{"a": [1]}]`, true},
+		{"1 in []", false},
+		{`"ell" in "hello"`, true},
+		{`"" in "hello"`, true},
+		{`"olé" in "hello"`, false},
+		{`"x" not in "hello"`, true},
+		{"2 in 0..3", true},
+		{"2 not in 0..3", false},
+		{`[1] in {"a": 1}`, "unusable as hash key: ARRAY"},
+		{`1 in "123"`, "unknown operator: INTEGER in STRING"},
+		{"1 not in true", "unknown operator: INTEGER in BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case bool:
+			if evaluated != nativeBoolToBooleanObject(expected) {
+				t.Errorf("%q: object is not %t. got=%T (%+v)", tt.input, expected, evaluated, evaluated)
+			}
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestStructuralEqualityAndOrdering(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"[1, 2] == [1, 2]", true},
+		{"let a = [1]; push(a, a); a == a", true},
+		{"let a = [1]; push(a, a); a in [a]", true},
+		{"let a = [1]; push(a, a); let b = [1]; push(b, b); a == b", true},
+		{"let a = [1]; push(a, a); a < a", false},
+		{"[1, 2] != [1, 2]", false},
+		{"[1, [2, 3]] == [1, [2, 3]]", true},
+		{"[1, 2] == [2, 1]", false},
+		{`{"a": [1], "b": 2} == {"b": 2, "a": [1]}`, true},
+		{`{"a": 1} == {"a": 2}`, false},
+		{`"abc" == "abc"`, true},
+		{`"abc" != "abd"`, true},
+		{"1 == true", false},
+		{"[] == []", true},
+		{"(0..3) == (0..=2)", true},
+		{"1 <= 1", true},
+		{"2 >= 3", false},
+		{`"apple" < "banana"`, true},
+		{`"b" > "abc"`, true},
+		{`"a" <= "a"`, true},
+		{`"" >= "a"`, false},
+		{"[1, 2] < [1, 3]", true},
+		{"[1, 2] > [1]", true},
+		{"[1] >= [1]", true},
+		{`[["a"], 2] < [["b"]]`, true},
+		{`1 < "a"`, "type mismatch: INTEGER < STRING"},
+		{"true < false", "unknown operator: BOOLEAN < BOOLEAN"},
+		{`[1] <= ["a"]`, "unknown operator: ARRAY <= ARRAY"},
+		{`{} > {}`, "unknown operator: HASH > HASH"},
+		{`"a" - "b"`, "unknown operator: STRING - STRING"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case bool:
+			if evaluated != nativeBoolToBooleanObject(expected) {
+				t.Errorf("%q: object is not %t. got=%T (%+v)", tt.input, expected, evaluated, evaluated)
+			}
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestTryCatch(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`try { throw "boom"; 1 } catch (e) { e.message }`, "boom"},
+		{`try { throw "boom" } catch (e) { e.type }`, "Error"},
+		{`try { throw "boom" } catch (e) { e.value }`, "boom"},
+		{`try { throw 42 } catch (e) { e.message }`, "42"},
+		{`try { throw 42 } catch (e) { e.value }`, 42},
+		{`try { 1 + true } catch (e) { e.message }`, "type mismatch: INTEGER + BOOLEAN"},
+		{`try { 1 + true } catch (e) { e.type }`, "RuntimeError"},
+		{`try { len(1) } catch (e) { e.message }`, "argument to `len` not supported, got INTEGER"},
+		{`try { 1 + true } catch (e) { e.value }`, nil},
+		{`try { throw {"type": "ValueError", "message": "bad"} } catch (e) { e.type + ": " + e.message }`, "ValueError: bad"},
+		{`try { throw {"code": 7} } catch (e) { e.value.code }`, 7},
+		{"try { 5 } catch (e) { 0 }", 5},
+		{"try { throw 1 } catch { 2 }", 2},
+		{"let x = try { throw 1 } catch { 3 }; x * 2;", 6},
+		{`try { try { throw "inner" } catch (e) { throw "outer: " + e.message } } catch (e) { e.message }`, "outer: inner"},
+		{`try { try { throw {"type": "T", "message": "m"} } catch (e) { throw e } } catch (e) { e.type + e.message }`, "Tm"},
+		{`try { throw "a" } catch (e) { 1 } e;`, "identifier not found: e"},
+		{`throw "uncaught"`, "uncaught"},
+		{`try { throw "a" } catch (e) { throw "b" }`, "b"},
+		{`try { 1 } catch (e) { throw e }`, 1},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if err, ok := evaluated.(*object.Error); ok {
+				testErrorObject(t, err, expected)
+				continue
+			}
+			str, ok := evaluated.(*object.String)
+			if !ok {
+				t.Errorf("%q: object is not String. got=%T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if str.Value != expected {
+				t.Errorf("%q: String has wrong value. want=%q, got=%q", tt.input, expected, str.Value)
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestTryFinally(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let x = 0; try { x = 1 } finally { x += 10 }; x;", 11},
+		{"let x = 0; try { throw 1 } catch { x = 1 } finally { x += 10 }; x;", 11},
+		{"try { 1 } finally { 2 }", 1},
+		{"try { throw 1 } catch { 3 } finally { 4 }", 3},
+		{"let log = []; let f = fn() { try { throw 1 } finally { push(log, 1) } }; try { f() } catch { len(log) };", 1},
+		{`try { throw "kept" } finally { 1 }`, "kept"},
+		{`try { 1 } finally { throw "from finally" }`, "from finally"},
+		{"let f = fn() { try { return 1 } finally { 2 } }; f();", 1},
+		{"let f = fn() { try { return 1 } finally { return 2 } }; f();", 2},
+		{
+			`let x = 0;
+			let f = fn() { try { return 1 } finally { x = 5 } };
+			f() + x;`,
+			6,
+		},
+		{
+			`let n = 0;
+			for (i in 0..10) {
+				try { if (i == 3) { break } } finally { n += 1 }
+			}
+			n;`,
+			4,
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestErrorStack(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			`fn inner() { throw "x" }
+			fn outer() { inner() }
+			try { outer() } catch (e) { e.stack }`,
+			[]string{"inner", "outer"},
+		},
+		{
+			`let f = fn() { 1 + true };
+			try { [1].map(x => f()) } catch (e) { e.stack }`,
+			[]string{"f", "<anonymous>"},
+		},
+		{`try { throw "top" } catch (e) { e.stack }`, []string{}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		array, ok := evaluated.(*object.Array)
+		if !ok {
+			t.Errorf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if len(array.Elements) != len(tt.expected) {
+			t.Errorf("wrong stack length. want=%d, got=%d (%s)",
+				len(tt.expected), len(array.Elements), array.Inspect())
+			continue
+		}
+
+		for i, name := range tt.expected {
+			if array.Elements[i].Inspect() != name {
+				t.Errorf("stack[%d] wrong. want=%q, got=%q", i, name, array.Elements[i].Inspect())
+			}
+		}
+	}
+}
+
+func TestResults(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"ok(5).unwrap()", 5},
+		{"ok(5).is_ok() ? 1 : 0", 1},
+		{"err(5).is_err() ? 1 : 0", 1},
+		{"err(1).unwrap_or(7)", 7},
+		{"ok(2).unwrap_or(7)", 2},
+		{"ok(1) == ok(1) ? 1 : 0", 1},
+		{"ok(1) == err(1) ? 1 : 0", 0},
+		{`err("bad").unwrap()`, `unwrap called on err("bad")`},
+		{"ok(1, 2)", "wrong number of arguments. got=2, want=1"},
+		{"err()", "wrong number of arguments. got=0, want=1"},
+		{
+			`let parse = fn(x) { x > 0 ? ok(x) : err("negative") };
+			let double = fn(x) { let v = parse(x)?; ok(v * 2) };
+			double(4).unwrap();`,
+			8,
+		},
+		{
+			`let parse = fn(x) { x > 0 ? ok(x) : err("negative") };
+			let double = fn(x) { let v = parse(x)?; ok(v * 2) };
+			double(-4).unwrap_or(0);`,
+			0,
+		},
+		{
+			`let calls = 0;
+			let f = fn() { err("stop")?; calls += 1; ok(1) };
+			f();
+			calls;`,
+			0,
+		},
+		{
+			`let sum = fn(xs) {
+				let total = 0;
+				for (x in xs) { total += x?; }
+				ok(total)
+			};
+			sum([ok(1), ok(2), ok(3)]).unwrap();`,
+			6,
+		},
+		{
+			`let sum = fn(xs) {
+				let total = 0;
+				for (x in xs) { total += x?; }
+				ok(total)
+			};
+			sum([ok(1), err(9), ok(3)]).is_err() ? 1 : 0;`,
+			1,
+		},
+		{"let f = fn(r) { [1, r?, 3] }; f(err(2)).unwrap_or(0)", 0},
+		{"let f = fn(r) { len([1, r?, 3]) }; f(ok(2))", 3},
+		{"let f = fn(r) { {\"a\": r?}[\"a\"] }; f(err(2)).is_err() ? 1 : 0", 1},
+		{"let f = fn(r) { put(r?); 1 }; f(err(2)).is_err() ? 1 : 0", 1},
+		{"let f = fn(r) { -r? }; f(err(2)).is_err() ? 1 : 0", 1},
+		{"ok(3)? + 1", 4},
+		{"ok(3)? - 1", 2},
+		{"ok([5])? [0]", 5},
+		{"ok(x => x * 2)? (4)", 8},
+		{`let x = err("bad")?; 1;`, `cannot propagate err("bad") with ` + "`?`" + ` outside a function`},
+		{`for (r in [ok(1), err(2)]) { r?; }`, "cannot propagate err(2) with `?` outside a function"},
+		{`let f = fn() { for (r in [err(2)]) { r?; } ok(0) }; f().unwrap_or(5);`, 5},
+		{"5?", "operand of `?` must be RESULT, got INTEGER"},
+		{"(1 + true)?", "type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestResultInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"ok(1)", "ok(1)"},
+		{"ok()", "ok(null)"},
+		{`err("bad")`, `err("bad")`},
+		{`let f = fn() { err("x")?; 1 }; f()`, `err("x")`},
+		{`ok([1, "a"])`, `ok([1, "a"])`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		result, ok := evaluated.(*object.Result)
+		if !ok {
+			t.Errorf("object is not Result. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if result.Inspect() != tt.expected {
+			t.Errorf("result.Inspect() wrong. want=%q, got=%q", tt.expected, result.Inspect())
+		}
+	}
+}
+
+func TestUnicodeStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`len("héllo")`, 5},
+		{`"世界".len()`, 2},
+		{`"héllo"[1]`, "é"},
+		{`"héllo"[-1]`, "o"},
+		{`"世界"[2]`, nil},
+		{`"héllo"[1:3]`, "él"},
+		{`"añb"[::-1]`, "bña"},
+		{`let café = "ü"; café + "!"`, "ü!"},
+		{`let n = 0; for (c in "añb") { n += 1 }; n;`, 3},
+		{`len(bytes("héllo"))`, 6},
+		{`bytes("é")[0]`, 195},
+		{`bytes("é")[1]`, 169},
+		{`len(runes("héllo"))`, 5},
+		{`runes("é")[0]`, 233},
+		{`"世".runes()[0]`, 19990},
+		{`bytes(1)`, "argument to `bytes` must be STRING, got INTEGER"},
+		{`runes("a", "b")`, "wrong number of arguments. got=2, want=1"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if err, ok := evaluated.(*object.Error); ok {
+				testErrorObject(t, err, expected)
+				continue
+			}
+			str, ok := evaluated.(*object.String)
+			if !ok {
+				t.Errorf("%q: object is not String. got=%T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if str.Value != expected {
+				t.Errorf("%q: String has wrong value. want=%q, got=%q", tt.input, expected, str.Value)
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestModules(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"lib/list.mk": `
+			import { inc } from "../util.mk";
+			export fn map(xs, f) { xs.map(f) }
+			export let incAll = fn(xs) { map(xs, inc) };
+			let hidden = 1;`,
+		"util.mk": `
+			export fn inc(x) { x + 1 }
+			export let log = [];
+			export let [first, ...others] = [1, 2, 3];`,
+		"cycle/a.mk":  `import "b.mk" as b;`,
+		"cycle/b.mk":  `import "a.mk" as a;`,
+		"broken.mk":   "let = 1;",
+		"failing.mk":  `export let x = 1 + true;`,
+		"search/s.mk": `export let found = 42;`,
+	})
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "lib/list.mk" as list; list.incAll([1, 2])[1];`, 3},
+		{`import { map, incAll } from "lib/list.mk"; map(incAll([1]), x => x * 10)[0];`, 20},
+		{`import "./util.mk" as u; u.first + len(u.others);`, 3},
+		{`import "util.mk" as a; import "util.mk" as b; push(a.log, 1); len(b.log);`, 1},
+		{`let f = fn() { import "util.mk" as u; u.inc(1) }; f();`, 2},
+		{`import "lib/list.mk" as list; list.hidden;`, "module " + filepath.Join(dir, "lib/list.mk") + " has no export hidden"},
+		{`import { hidden } from "lib/list.mk";`, "module lib/list.mk has no export hidden"},
+		{`import "missing.mk" as m;`, "module not found: missing.mk"},
+		{`import "s.mk" as s;`, "module not found: s.mk"},
+		{`import "cycle/a.mk" as a;`, "import cycle: " + strings.Join([]string{
+			filepath.Join(dir, "cycle/a.mk"),
+			filepath.Join(dir, "cycle/b.mk"),
+			filepath.Join(dir, "cycle/a.mk"),
+		}, " -> ")},
+		{`import "broken.mk" as b;`, "parse errors in module " + filepath.Join(dir, "broken.mk") +
+			": expected next token to be IDENT, got let instead; no prefix parse function for = found"},
+		{`import "failing.mk" as f;`, "type mismatch: INTEGER + BOOLEAN"},
+		{`try { import "missing.mk" as m; } catch (e) { 7 }`, 7},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalFile(filepath.Join(dir, "main.mk"), tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestModuleSearchPath(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"search/strings.mk": `export fn shout(s) { s.upper() + "!" }`,
+		"search/local.mk":   `export let x = 1;`,
+	})
+
+	defer func(saved []string) { SearchPath = saved }(SearchPath)
+	SearchPath = []string{filepath.Join(dir, "search")}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "strings.mk" as s; s.shout("hi");`, "HI!"},
+		{`import "./local.mk" as l; l.x;`, "module not found: ./local.mk"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalFile(filepath.Join(dir, "main.mk"), tt.input)
+
+		if err, ok := evaluated.(*object.Error); ok {
+			testErrorObject(t, err, tt.expected)
+			continue
+		}
+		str, ok := evaluated.(*object.String)
+		if !ok || str.Value != tt.expected {
+			t.Errorf("%q: wrong result. want=%q, got=%+v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestResetModules(t *testing.T) {
+	dir := writeModules(t, map[string]string{"m.mk": `export let x = 1;`})
+	main := filepath.Join(dir, "main.mk")
+
+	testIntegerObject(t, testEvalFile(main, `import "m.mk" as m; m.x;`), 1)
+
+	if err := os.WriteFile(filepath.Join(dir, "m.mk"), []byte(`export let x = 2;`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	testIntegerObject(t, testEvalFile(main, `import "m.mk" as m; m.x;`), 2)
+}
+
+func writeModules(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, source := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func testEvalFile(path, input string) object.Object {
+	l := lexer.New(input)
+	p := parse.New(l)
+	ResetModules()
+	env := object.NewEnvironment()
+	env.SetFile(path)
+	program := p.ParseProgram()
+	return Eval(program, env)
+}
+
+func TestStdlib(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"map([1, 2, 3], fn(x) { x * 2 })[2]", 6},
+		{"len(filter(0..10, fn(x) { x > 6 }))", 3},
+		{"reduce([1, 2, 3], fn(a, b) { a + b }, 10)", 16},
+		{"let n = 0; each([1, 2], fn(x) { n += x }); n;", 3},
+		{"find([1, 4, 9], fn(x) { x > 3 })", 4},
+		{"find([1], fn(x) { x > 3 })", nil},
+		{"any([1, 2], fn(x) { x > 1 }) ? 1 : 0", 1},
+		{"all([1, 2], fn(x) { x > 1 }) ? 1 : 0", 0},
+		{"count(1..=10, fn(x) { x > 7 })", 3},
+		{"sum([1, 2, 3, 4])", 10},
+		{"sum([])", 0},
+		{"product(1..=5)", 120},
+		{"max([3, 9, 2])", 9},
+		{"min([3, 9, 2])", 2},
+		{"max([])", nil},
+		{"reverse([1, 2, 3])[0]", 3},
+		{"len(take([1, 2, 3], 2)) + len(drop([1, 2, 3], 2))", 3},
+		{"zip([1, 2, 3], [4, 5])[1][1]", 5},
+		{"len(flatten([[1], [2, 3], []]))", 3},
+		{"identity(7)", 7},
+		{"constant(7)()", 7},
+		{"compose(fn(x) { x + 1 }, fn(x) { x * 2 })(5)", 11},
+		{"flip(fn(a, b) { a - b })(1, 10)", 9},
+		{"curry(fn(a, b) { a - b })(10)(1)", 9},
+		{"uncurry(curry(fn(a, b) { a - b }))(10, 1)", 9},
+		{"times(3, fn(i) { i * i })[2]", 4},
+		{`join(["a", "b", "c"], ", ")`, "a, b, c"},
+		{`join([], ", ")`, ""},
+		{`repeat("ab", 3)`, "ababab"},
+		{`pad_left("7", 3, "0")`, "007"},
+		{`pad_right("ab", 4, ".")`, "ab.."},
+		{`capitalize("héllo")`, "Héllo"},
+		{`[1, 2, 3] |> map(x => x + 1) |> sum`, 9},
+		{"let sum = fn(xs) { 0 }; sum([1, 2]);", 0},
+		{"let f = fn() { sum }; let sum = 5; f();", 5},
+		{"not_a_stdlib_function", "identifier not found: not_a_stdlib_function"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if err, ok := evaluated.(*object.Error); ok {
+				testErrorObject(t, err, expected)
+				continue
+			}
+			str, ok := evaluated.(*object.String)
+			if !ok {
+				t.Errorf("%q: object is not String. got=%T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if str.Value != expected {
+				t.Errorf("%q: String has wrong value. want=%q, got=%q", tt.input, expected, str.Value)
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestStdlibGlobalsAreFunctions(t *testing.T) {
+	if stdlibIndex == nil {
+		stdlibIndex = indexStdlib()
+	}
+
+	if len(stdlibIndex) == 0 {
+		t.Fatalf("standard library exports nothing")
+	}
+
+	for name, file := range stdlibIndex {
+		global, ok := stdlibGlobal(name)
+		if !ok {
+			t.Errorf("%s (%s) is not a global", name, file)
+			continue
+		}
+		if _, ok := global.(*object.Function); !ok {
+			t.Errorf("%s (%s) is not a Function. got=%T (%+v)", name, file, global, global)
+		}
+	}
+}
+
+func TestArrayBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"map([1, 2, 3], x => x * 2)", []int64{2, 4, 6}},
+		{"map(1..=3, x => x * x)", []int64{1, 4, 9}},
+		{"map(0..9000000000000000000, x => x)", "range 0..9000000000000000000 is too long for `map`, max 16777216 elements"},
+		{"any(0..9000000000000000000, x => true)", "range 0..9000000000000000000 is too long for `any`, max 16777216 elements"},
+		{"[1, 2].map(len)", "argument to `len` not supported, got INTEGER"},
+		{"filter([1, 2, 3, 4], x => x > 2)", []int64{3, 4}},
+		{"reduce([1, 2, 3], (a, b) => a + b, 10)", 16},
+		{"reduce([1, 2, 3], (a, b) => a * b)", 6},
+		{"[[1], [2]].reduce((a, b) => a + b[0], 0)", 3},
+		{"reduce([], (a, b) => a + b)", "reduce of empty array with no initial value"},
+		{"let n = 0; each([1, 2, 3], x => n += x); n;", 6},
+		{"each([1], x => x)", nil},
+		{"find([1, 4, 9], x => x > 3)", 4},
+		{"find([1], x => x > 3)", nil},
+		{"any([1, 2], x => x > 1)", true},
+		{"any([], x => true)", false},
+		{"all([1, 2], x => x > 1)", false},
+		{"all([], x => false)", true},
+		{"zip([1, 2, 3], [4, 5])", [][]int64{{1, 4}, {2, 5}}},
+		{"zip([1, 2], [3, 4], [5, 6])", [][]int64{{1, 3, 5}, {2, 4, 6}}},
+		{"flatten([[1], [2, 3], [], 4])", []int64{1, 2, 3, 4}},
+		{"sort([3, 1, 2])", []int64{1, 2, 3}},
+		{"[3, 1, 2].sort((a, b) => b - a)", []int64{3, 2, 1}},
+		{"sort([3, 1, 2], (a, b) => a > b)", []int64{3, 2, 1}},
+		{`sort(["b", "a"])[0]`, "a"},
+		{"sort([[2], [1, 5], [1]])", [][]int64{{1}, {1, 5}, {2}}},
+		{`sort([1, "a"])`, "cannot compare STRING and INTEGER"},
+		{"sort([1, 2], (a, b) => 0)", []int64{1, 2}},
+		{`sort([1, 2], (a, b) => "x")`, "comparator must return INTEGER or BOOLEAN, got STRING"},
+		{"sort([1, 2], (a, b) => a + true)", "type mismatch: INTEGER + BOOLEAN"},
+		{"let xs = [2, 1]; sort(xs); xs;", []int64{2, 1}},
+		{"reverse([1, 2, 3])", []int64{3, 2, 1}},
+		{"index_of([1, 2, 3], 2)", 1},
+		{"index_of([[1], [2]], [2])", 1},
+		{"index_of([1, 2, 3], 5)", -1},
+		{"concat([1], [2, 3], [])", []int64{1, 2, 3}},
+		{"concat()", []int64{}},
+		{"unique([1, 2, 1, 3, 2])", []int64{1, 2, 3}},
+		{`len(unique([[1], [1], "a", "a", {}, {}]))`, 3},
+		{"map(1, x => x)", "argument to `map` must be ARRAY, got INTEGER"},
+		{"sort()", "wrong number of arguments. got=0, want=1 or 2"},
+		{"zip([1])", "wrong number of arguments. got=1, want at least 2"},
+		{"filter([1])", "wrong number of arguments. got=1, want=2"},
+		{"concat([1], 2)", "argument to `concat` must be ARRAY, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			if evaluated != nativeBoolToBooleanObject(expected) {
+				t.Errorf("%q: object is not %t. got=%T (%+v)", tt.input, expected, evaluated, evaluated)
+			}
+		case string:
+			if str, ok := evaluated.(*object.String); ok {
+				if str.Value != expected {
+					t.Errorf("%q: String has wrong value. want=%q, got=%q", tt.input, expected, str.Value)
+				}
+				continue
+			}
+			testErrorObject(t, evaluated, expected)
+		case []int64:
+			testIntegerArray(t, evaluated, expected)
+		case [][]int64:
+			array, ok := evaluated.(*object.Array)
+			if !ok || len(array.Elements) != len(expected) {
+				t.Errorf("%q: wrong result. got=%T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			for i, inner := range expected {
+				testIntegerArray(t, array.Elements[i], inner)
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+func TestStringBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`split("a,b,,c", ",")`, []string{"a", "b", "", "c"}},
+		{`split("  héllo   wörld ")`, []string{"héllo", "wörld"}},
+		{`"añb".split("")`, []string{"a", "ñ", "b"}},
+		{`join(["a", "b", "c"], "-")`, "a-b-c"},
+		{`["x"].join(", ")`, "x"},
+		{`join(0..2, ",")`, "argument to `join` must be ARRAY of STRING, got INTEGER element"},
+		{"trim(\"\t hi \n\")", "hi"},
+		{`trim("xxhixx", "x")`, "hi"},
+		{`" hi ".trim_left()`, "hi "},
+		{`trim_right("hi!!", "!")`, "hi"},
+		{`upper("héllo")`, "HÉLLO"},
+		{`"ÉCOLE".lower()`, "école"},
+		{`replace("a-b-c", "-", "+")`, "a+b+c"},
+		{`contains("héllo", "éll")`, true},
+		{`"abc".contains("d")`, false},
+		{`starts_with("monkey", "mon")`, true},
+		{`ends_with("monkey", "mon")`, false},
+		{`index_of("héllo", "l")`, 2},
+		{`"abc".index_of("z")`, -1},
+		{`repeat("ab", 3)`, "ababab"},
+		{`repeat("ab", -1)`, "argument to `repeat` must not be negative, got -1"},
+		{`repeat("ab", 4611686018427387904)`, "result of `repeat` is too long, max 268435456 bytes"},
+		{`repeat("", 4611686018427387904)`, ""},
+		{`pad_left("7", 3)`, "  7"},
+		{`pad_left("é", 4, "ab")`, "abaé"},
+		{`"ab".pad_right(4, ".")`, "ab.."},
+		{`pad_right("abcd", 2)`, "abcd"},
+		{`pad_left("a", 9223372036854775807)`, "width of `pad_left` is too large, max 268435456"},
+		{`chars("añ")`, []string{"a", "ñ"}},
+		{`chars("")`, []string{}},
+		{`format("%s is %d", "x", 42)`, "x is 42"},
+		{`format("%05d|%-3s|%t", 7, "é", true)`, "00007|é  |true"},
+		{`format("%v", 0..3)`, "0..3"},
+		{`"%s!".format("hi")`, "hi!"},
+		{`upper(1)`, "argument to `upper` must be STRING, got INTEGER"},
+		{`contains("a")`, "wrong number of arguments. got=1, want=2"},
+		{`format()`, "wrong number of arguments. got=0, want at least 1"},
+		{`format("%d%% %.2f %x", 50, 1.5, 255)`, "50% 1.50 ff"},
+		{`format("%d %s", 1)`, "wrong number of arguments to `format`. got=1, want=2"},
+		{`format("%d", 1, 2)`, "wrong number of arguments to `format`. got=2, want=1"},
+		{`format("%q", 1)`, "verb %q in `format` does not suit INTEGER"},
+		{`format("%d", "x")`, "verb %d in `format` does not suit STRING"},
+		{`format("%s", [1])`, "[1]"},
+		{`format("%*d", 3, 1)`, `format "%*d" uses *, which ` + "`format`" + ` does not support`},
+		{`format("50%")`, `format "50%" ends in an incomplete verb`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			if evaluated != nativeBoolToBooleanObject(expected) {
+				t.Errorf("%q: object is not %t. got=%T (%+v)", tt.input, expected, evaluated, evaluated)
+			}
+		case string:
+			if str, ok := evaluated.(*object.String); ok {
+				if str.Value != expected {
+					t.Errorf("%q: String has wrong value. want=%q, got=%q", tt.input, expected, str.Value)
+				}
+				continue
+			}
+			testErrorObject(t, evaluated, expected)
+		case []string:
+			array, ok := evaluated.(*object.Array)
+			if !ok || len(array.Elements) != len(expected) {
+				t.Errorf("%q: wrong result. got=%T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			for i, want := range expected {
+				str, ok := array.Elements[i].(*object.String)
+				if !ok || str.Value != want {
+					t.Errorf("%q: element %d wrong. want=%q, got=%+v", tt.input, i, want, array.Elements[i])
+				}
+			}
+		}
+	}
+}
+
+func TestHashBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"keys({3: 1, 1: 2, 2: 3})", []int64{3, 1, 2}},
+		{`keys({"b": 1, "a": 2, "c": 3}).join(",")`, "b,a,c"},
+		{`values({"b": 1, "a": 2})`, []int64{1, 2}},
+		{"keys({})", []int64{}},
+		{"entries({2: 20, 1: 10})[0]", []int64{2, 20}},
+		{"len(entries({1: 1, 2: 2}))", 2},
+		{`has({"a": 1}, "a")`, true},
+		{`{"a": 1}.has("b")`, false},
+		{`has({}, [])`, "unusable as hash key: ARRAY"},
+		{`let h = {"a": 1, "b": 2}; len(delete(h, "a"));`, 1},
+		{`let h = {"a": 1, "b": 2}; delete(h, "a"); len(h);`, 2},
+		{`has(delete({"a": 1, "b": 2}, "a", "b"), "b")`, false},
+		{`merge({"a": 1, "b": 2}, {"b": 3})["b"]`, 3},
+		{`keys(merge({"a": 1, "b": 2}, {"c": 3, "a": 4})).join("")`, "abc"},
+		{`keys(delete({"a": 1, "b": 2, "c": 3}, "b")).join("")`, "ac"},
+		{`keys(from_entries([["z", 1], ["y", 2]])).join("")`, "zy"},
+		{`let s = ""; let f = fn(x) { s += x; x }; {f("b"): f("1"), f("a"): f("2")}; s;`, "b1a2"},
+		{`len(merge({"a": 1}, {"b": 2}, {"c": 3}))`, 3},
+		{`let h = {"a": 1}; merge(h, {"a": 2}); h["a"];`, 1},
+		{`get({"a": 1}, "a", 0)`, 1},
+		{`get({"a": 1}, "b", 0)`, 0},
+		{`{"a": 1}.get("b")`, nil},
+		{`from_entries([["a", 1], ["b", 2]])["b"]`, 2},
+		{`let h = {1: "x", 2: "y"}; from_entries(entries(h)) == h;`, true},
+		{`from_entries([1])`, "entry of `from_entries` must be a [key, value] ARRAY, got 1"},
+		{`len({"a": 1, "b": 2})`, 2},
+		{`{"keys": 1}.keys`, 1},
+		{`keys([1])`, "argument to `keys` must be HASH, got ARRAY"},
+		{`merge()`, "wrong number of arguments. got=0, want at least 1"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			if evaluated != nativeBoolToBooleanObject(expected) {
+				t.Errorf("%q: object is not %t. got=%T (%+v)", tt.input, expected, evaluated, evaluated)
+			}
+		case string:
+			if str, ok := evaluated.(*object.String); ok {
+				if str.Value != expected {
+					t.Errorf("%q: String has wrong value. want=%q, got=%q", tt.input, expected, str.Value)
+				}
+				continue
+			}
+			testErrorObject(t, evaluated, expected)
+		case []int64:
+			testIntegerArray(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestTuples(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let cache = {(1, 2): 3}; cache[(1, 2)];", 3},
+		{"let x = 1; let cache = {(x, x + 1): 3}; cache[(1, 2)];", 3},
+		{"{(1, 2): 3}[(2, 1)]", nil},
+		{`{(1, "a"): 1, (1, "a"): 2}[(1, "a")]`, 2},
+		{`len({(1, "a"): 1, (1, "a"): 2})`, 1},
+		{"{((1, 2), true): 4}[((1, 2), true)]", 4},
+		{"{(1,): 5}[(1,)]", 5},
+		{"{(): 6}[()]", 6},
+		{"{1: 1}[(1,)]", nil},
+		{"(1, 2) in {(1, 2): 0}", true},
+		{"{([1], 2): 0}", "unusable as hash key: TUPLE"},
+		{"{[1, 2]: 0}", "unusable as hash key: ARRAY"},
+		{"{}[([1],)]", "unusable as hash key: TUPLE"},
+		{"(1, 2) == (1, 2)", true},
+		{"(1, 2) == [1, 2]", false},
+		{"(1, 2) < (1, 3)", true},
+		{"len((1, 2, 3))", 3},
+		{"(1, 2, 3)[-1]", 3},
+		{"(1, 2, 3)[1:]", "(2, 3)"},
+		{"2 in (1, 2)", true},
+		{"let [a, b] = (1, 2); a + b;", 3},
+		{"let n = 0; for (x in (1, 2, 3)) { n += x; } n;", 6},
+		{"map((1, 2), x => x * 2)", []int64{2, 4}},
+		{"to_array((1, 2))", []int64{1, 2}},
+		{"tuple([1, 2])", "(1, 2)"},
+		{"tuple(0..3) == (0, 1, 2)", true},
+		{"unique([(1, 2), (1, 2), (2, 1)]).len()", 2},
+		{"let xs = [1]; let t = tuple(xs); push(xs, 2); len(t);", 1},
+		{"(1,)", "(1,)"},
+		{"tuple(1)", "argument to `tuple` not supported, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			if evaluated != nativeBoolToBooleanObject(expected) {
+				t.Errorf("%q: object is not %t. got=%T (%+v)", tt.input, expected, evaluated, evaluated)
+			}
+		case string:
+			if tuple, ok := evaluated.(*object.Tuple); ok {
+				if tuple.Inspect() != expected {
+					t.Errorf("%q: wrong tuple. want=%s, got=%s", tt.input, expected, tuple.Inspect())
+				}
+				continue
+			}
+			testErrorObject(t, evaluated, expected)
+		case []int64:
+			testIntegerArray(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestTypeBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"type(1)", "INTEGER"},
+		{"type(1.5)", "FLOAT"},
+		{`type("a")`, "STRING"},
+		{"type((1, 2))", "TUPLE"},
+		{"type(len)", "BUILTIN"},
+		{"type(if (false) { 1 })", "NULL"},
+		{`"n=" + str(5)`, "n=5"},
+		{`str("a")`, "a"},
+		{"str(2.0)", "2.0"},
+		{"str(true)", "true"},
+		{"str(0..3)", "0..3"},
+		{`int("42")`, 42},
+		{`int(" -7 ")`, -7},
+		{"int(3.9)", 3},
+		{"int(-3.9)", -3},
+		{"int(true)", 1},
+		{`int("4x")`, `cannot convert "4x" to INTEGER`},
+		{`int("")`, `cannot convert "" to INTEGER`},
+		{"int([])", "argument to `int` not supported, got ARRAY"},
+		{`float("2.5")`, 2.5},
+		{"float(2)", 2.0},
+		{"float(false)", 0.0},
+		{`float("x")`, `cannot convert "x" to FLOAT`},
+		{"bool(0)", true},
+		{`bool("")`, true},
+		{"bool(false)", false},
+		{"bool(if (false) { 1 })", false},
+		{"is_int(1)", true},
+		{"is_int(1.0)", false},
+		{"is_number(1.0)", true},
+		{`is_string("a")`, true},
+		{"is_bool(false)", true},
+		{"is_null(if (false) { 1 })", true},
+		{"is_array((1, 2))", false},
+		{"is_tuple((1, 2))", true},
+		{"is_hash({})", true},
+		{"is_range(0..1)", true},
+		{"is_result(ok(1))", true},
+		{"is_function(len)", true},
+		{"is_function(x => x)", true},
+		{"is_function(1)", false},
+		{"is_int()", "wrong number of arguments. got=0, want=1"},
+		{"1.5 + 1", 2.5},
+		{"1 / 4.0", 0.25},
+		{"-1.5 * 2", -3.0},
+		{"0.1 + 0.2 > 0.3", true},
+		{"1 == 1.0", true},
+		{"2.5 <= 2", false},
+		{"[1] == [1.0]", true},
+		{"(1, 2.5) < (1.0, 3)", true},
+		{"1 in [1.0]", true},
+		{"len(unique([1, 1.0, 2]))", 2},
+		{`{1: "a"}[1.0]`, "a"},
+		{`{1.0: "a"}[1]`, "a"},
+		{`{(1, 2): "a"}[(1.0, 2)]`, "a"},
+		{"1.0 in {1: 2}", true},
+		{`{1.5: "a"}`, "unusable as hash key: FLOAT"},
+		{"str(sort([2, 1.5, 1]))", "[1, 1.5, 2]"},
+		{"1.5 + true", "type mismatch: FLOAT + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			float, ok := evaluated.(*object.Float)
+			if !ok || float.Value != expected {
+				t.Errorf("%q: wrong float. want=%g, got=%T (%+v)", tt.input, expected, evaluated, evaluated)
+			}
+		case bool:
+			if evaluated != nativeBoolToBooleanObject(expected) {
+				t.Errorf("%q: object is not %t. got=%T (%+v)", tt.input, expected, evaluated, evaluated)
+			}
+		case string:
+			if str, ok := evaluated.(*object.String); ok {
+				if str.Value != expected {
+					t.Errorf("%q: String has wrong value. want=%q, got=%q", tt.input, expected, str.Value)
+				}
+				continue
+			}
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`[1, "a", [true, "b"]]`, `[1, "a", [true, "b"]]`},
+		{`{"name": "Ann", "tags": ["x"], 2: (1, "y")}`, `{"name": "Ann", "tags": ["x"], 2: (1, "y")}`},
+		{`"plain"`, "plain"},
+		{`str(["a"])`, `["a"]`},
+		{`repr("a")`, `"a"`},
+		{`repr("say \"hi\"\n")`, `"say \"hi\"\n"`},
+		{`repr(1.0)`, "1.0"},
+		{`1e21`, "1e+21"},
+		{`2.5E-7 * 2`, "5e-07"},
+		{`fn add(a, b) { a + b }; add;`, "fn add(a, b)"},
+		{`x => x`, "fn(x)"},
+		{`fn([a, b], {c}) { a }`, "fn([a, b], {c})"},
+		{`len`, "builtin len"},
+		{`map`, "builtin map"},
+		{`"a".upper`, "builtin STRING.upper"},
+		{`let f = fn() { 1 }; [len, f];`, "[builtin len, fn f()]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%q: Inspect wrong. want=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestReprRoundTrip(t *testing.T) {
+	inputs := []string{
+		`[1, "a\"b", -2, true]`,
+		`{"k": [1, (2,)], (1, "x"): {"\\": "\n"}}`,
+		`[ok("a"), err([1]), (), 0..3, 1.5]`,
+		`[1e21, 1e-7, -2.5e300, 0.1, 100.0]`,
+	}
+
+	for _, input := range inputs {
+		value := testEval(input)
+		repr := testEval("repr(" + input + ")")
+		str, ok := repr.(*object.String)
+		if !ok {
+			t.Fatalf("%q: repr did not return String. got=%T (%+v)", input, repr, repr)
+		}
+
+		again := testEval(str.Value)
+		if !object.Equals(value, again) {
+			t.Errorf("%q: repr %s reads back as %s", input, str.Value, again.Inspect())
+		}
+	}
+}
+
+
+func testIntegerArray(t *testing.T, obj object.Object, expected []int64) bool {
+	array, ok := obj.(*object.Array)
+	if !ok {
+		t.Errorf("object is not Array. got=%T (%+v)", obj, obj)
+		return false
+	}
+
+	if len(array.Elements) != len(expected) {
+		t.Errorf("wrong num of elements. want=%d, got=%d", len(expected), len(array.Elements))
+		return false
+	}
+
+	for i, want := range expected {
+		if !testIntegerObject(t, array.Elements[i], want) {
+			return false
+		}
+	}
+	return true
+}
+
+
+func testErrorObject(t *testing.T, obj object.Object, expected string) bool {
+	errObj, ok := obj.(*object.Error)
+	if !ok {
+		t.Errorf("no error object returned. got=%T(%+v)", obj, obj)
+		return false
+	}
+
+	if errObj.Message != expected {
+		t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+		return false
+	}
+	return true
+}
+
 
 func testNullObject(t *testing.T, obj object.Object) bool {
 	if obj != NULL {
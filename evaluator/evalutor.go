@@ -2,8 +2,11 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
 	"monkey/ast"
 	"monkey/object"
+	"strings"
+	"unicode/utf8"
 )
 
 var (
@@ -11,8 +14,12 @@ var (
 	FALSE = &object.Boolean{Value: false}
 	NULL  = &object.Null{}
 
+	BREAK    = &object.Break{}
+	CONTINUE = &object.Continue{}
+
 	builtins = map[string]*object.Builtin {
 		"len": {
+			Name: "len",
 			Fn: func(args ...object.Object) object.Object {
 				if len(args) != 1 {
 					return newError("wrong number of arguments. got=%d, want=1", len(args))
@@ -20,15 +27,22 @@ var (
 
 				switch arg := args[0].(type) {
 				case *object.Array:
-					return &object.Array{Elements: args}
+					return &object.Integer{Value: int64(len(arg.Elements))}
+				case *object.Tuple:
+					return &object.Integer{Value: int64(len(arg.Elements))}
 				case *object.String:
-					return &object.Integer{Value: int64(len(arg.Value))}
+					return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+				case *object.Range:
+					return &object.Integer{Value: arg.Len()}
+				case *object.Hash:
+					return &object.Integer{Value: int64(arg.Len())}
 				default:
 					return newError("argument to `len` not supported, got %s", args[0].Type())
 				}
 			},
 		},
 		"first": {
+			Name: "first",
 			Fn: func(args ...object.Object) object.Object {
 				if len(args) != 1 {
 					return newError("wrong number of arguments. got=%d, want=1", len(args))
@@ -45,6 +59,7 @@ var (
 			},
 		},
 		"last": {
+			Name: "last",
 			Fn: func(args ...object.Object) object.Object {
 				if len(args) != 1 {
 					return newError("wrong number of arguments. got=%d, want=1", len(args))
@@ -61,6 +76,7 @@ var (
 			},
 		},
 		"rest": {
+			Name: "rest",
 			Fn: func(args ...object.Object) object.Object {
 				if len(args) != 1 {
 					return newError("wrong number of arguments. got=%d, want=1", len(args))
@@ -80,6 +96,7 @@ var (
 			},
 		},
 		"push": {
+			Name: "push",
 			Fn: func(args ...object.Object) object.Object {
 				if len(args) != 2 {
 					return newError("wrong number of arguments. got=%d, want=1", len(args))
@@ -93,7 +110,111 @@ var (
 				return array
 			},
 		},
+		"to_array": {
+			Name: "to_array",
+			Fn: func(args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return newError("wrong number of arguments. got=%d, want=1", len(args))
+				}
+
+				switch arg := args[0].(type) {
+				case *object.Array:
+					return arg
+				case *object.Tuple:
+					return &object.Array{Elements: append([]object.Object{}, arg.Elements...)}
+				case *object.Range:
+					array, err := rangeToArray("to_array", arg)
+					if err != nil {
+						return err
+					}
+					return array
+				default:
+					return newError("argument to `to_array` not supported, got %s", args[0].Type())
+				}
+			},
+		},
+		"tuple": {
+			Name: "tuple",
+			Fn: func(args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return newError("wrong number of arguments. got=%d, want=1", len(args))
+				}
+
+				switch arg := args[0].(type) {
+				case *object.Tuple:
+					return arg
+				case *object.Array:
+					return &object.Tuple{Elements: append([]object.Object{}, arg.Elements...)}
+				case *object.Range:
+					array, err := rangeToArray("tuple", arg)
+					if err != nil {
+						return err
+					}
+					return &object.Tuple{Elements: array.Elements}
+				default:
+					return newError("argument to `tuple` not supported, got %s", args[0].Type())
+				}
+			},
+		},
+		"bytes": {
+			Name: "bytes",
+			Fn: func(args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return newError("wrong number of arguments. got=%d, want=1", len(args))
+				}
+				if args[0].Type() != object.STRING_OBJ {
+					return newError("argument to `bytes` must be STRING, got %s", args[0].Type())
+				}
+
+				value := args[0].(*object.String).Value
+				elements := make([]object.Object, len(value))
+				for i := 0; i < len(value); i++ {
+					elements[i] = &object.Integer{Value: int64(value[i])}
+				}
+				return &object.Array{Elements: elements}
+			},
+		},
+		"runes": {
+			Name: "runes",
+			Fn: func(args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return newError("wrong number of arguments. got=%d, want=1", len(args))
+				}
+				if args[0].Type() != object.STRING_OBJ {
+					return newError("argument to `runes` must be STRING, got %s", args[0].Type())
+				}
+
+				elements := []object.Object{}
+				for _, r := range args[0].(*object.String).Value {
+					elements = append(elements, &object.Integer{Value: int64(r)})
+				}
+				return &object.Array{Elements: elements}
+			},
+		},
+		"ok": {
+			Name: "ok",
+			Fn: func(args ...object.Object) object.Object {
+				switch len(args) {
+				case 0:
+					return &object.Result{Ok: true, Value: NULL}
+				case 1:
+					return &object.Result{Ok: true, Value: args[0]}
+				default:
+					return newError("wrong number of arguments. got=%d, want=1", len(args))
+				}
+			},
+		},
+		"err": {
+			Name: "err",
+			Fn: func(args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return newError("wrong number of arguments. got=%d, want=1", len(args))
+				}
+				return &object.Result{Ok: false, Value: args[0]}
+			},
+		},
 		"put": {
+			Name: "put",
 			Fn: func(args ...object.Object) object.Object {
 				for _, arg := range args {
 					fmt.Println(arg.Inspect())
@@ -116,17 +237,21 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return nativeBoolToBooleanObject(node.Value)
 	case *ast.PrefixExpression:
 		right := Eval(node.Right, env)
-		if isError(right) {
+		if isAbrupt(right) {
 			return right
 		}
 		return evalPrefixExpression(node.Operator, right)
 	case *ast.InfixExpression:
 		left := Eval(node.Left, env)
-		if isError(left) {
+		if isAbrupt(left) {
+			return left
+		}
+		if node.Operator == "??" && left != NULL {
+			// the fallback is only evaluated when it is needed
 			return left
 		}
 		right := Eval(node.Right, env)
-		if isError(right) {
+		if isAbrupt(right) {
 			return right
 		}
 		return evalInfixExpression(node.Operator, left, right)
@@ -134,15 +259,25 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalBlockStatments(node.Statements, env)
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
+	case *ast.MatchExpression:
+		return evalMatchExpression(node, env)
+	case *ast.ConditionalExpression:
+		return evalConditionalExpression(node, env)
 	case *ast.ReturnStatement:
 		result := Eval(node.ReturnValue, env)
-		if isError(result) {
+		if isAbrupt(result) {
 			return result
 		}
 		return &object.ReturnValue{Value: result}
 	case *ast.LetStatement:
 		val := Eval(node.Value, env)
-		if isError(val) {
+		if isAbrupt(val) {
+			return val
+		}
+		if node.Pattern != nil {
+			if err := bindPattern(node.Pattern, val, env); err != nil {
+				return err
+			}
 			return val
 		}
 		return env.Set(node.Name.Value, val)
@@ -151,38 +286,56 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.FunctionLiteral:
 		params := node.Parameters
 		body := node.Body
-		return &object.Function{Parameters: params, Body: body, Env: env}
-	case *ast.CallExpression:
-		function := Eval(node.Function, env)
-		if isError(function) {
-			return function
-		}
-		args := evalExpression(node.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
-			return args[0]
-		}
-
-		return applyFunction(function, args)
+		return &object.Function{Parameters: params, Body: body, Env: env, Name: node.Name}
+	case *ast.FunctionStatement:
+		// bound by hoistFunctions when the enclosing block was entered
+		fn, _ := env.Get(node.Name.Value)
+		return fn
+	case *ast.CallExpression, *ast.IndexExpression, *ast.SliceExpression, *ast.PropertyExpression:
+		result, _ := evalChain(node.(ast.Expression), env)
+		return result
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
 	case *ast.ArrayLiteral:
 		elements := evalExpression(node.Elements, env)
-		if len(elements) == 1 && isError(elements[0]) {
+		if len(elements) == 1 && isAbrupt(elements[0]) {
 			return elements[0]
 		}
 		return &object.Array{Elements: elements}
-	case *ast.IndexExpression:
-		left := Eval(node.Left, env)
-		if isError(left) {
-			return left
-		}
-		index := Eval(node.Index, env)
-		if isError(index) {
-			return index
+	case *ast.TupleLiteral:
+		elements := evalExpression(node.Elements, env)
+		if len(elements) == 1 && isAbrupt(elements[0]) {
+			return elements[0]
 		}
-		return evalIndexExpression(left, index)
+		return &object.Tuple{Elements: elements}
+	case *ast.RangeExpression:
+		return evalRangeExpression(node, env)
+	case *ast.ThrowStatement:
+		return evalThrowStatement(node, env)
+	case *ast.TryExpression:
+		return evalTryExpression(node, env)
+	case *ast.PropagateExpression:
+		return evalPropagateExpression(node, env)
+	case *ast.ImportStatement:
+		return evalImportStatement(node, env)
+	case *ast.ExportStatement:
+		return Eval(node.Statement, env)
 	case *ast.HashLiteral:
 		return evalHashLiteral(node, env)
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, env)
+	case *ast.WhileStatement:
+		return evalWhileStatement(node, env)
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+	case *ast.ForInStatement:
+		return evalForInStatement(node, env)
+	case *ast.BreakStatement:
+		return BREAK
+	case *ast.ContinueStatement:
+		return CONTINUE
 	default:
 		return nil
 	}
@@ -197,27 +350,124 @@ func applyFunction(fn object.Object, args []object.Object) object.Object {
 	case *object.Builtin:
 		return fn.Fn(args...)
 	case *object.Function:
-		extendedEnv := extendedFunctionEnv(fn, args)
+		extendedEnv, err := extendedFunctionEnv(fn, args)
+		if err != nil {
+			return err
+		}
 		evaluated := Eval(fn.Body, extendedEnv)
+		if err, ok := evaluated.(*object.Error); ok {
+			err.Stack = append(err.Stack, functionName(fn))
+		}
 		return unwarpReturnValue(evaluated)
 	}
 
 	return newError("not a function: %s", fn.Type())
 }
 
-func extendedFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
-	env := object.NewEnclosedEnvironment(fn.Env)
+func functionName(fn *object.Function) string {
+	if fn.Name == "" {
+		return "<anonymous>"
+	}
+	return fn.Name
+}
+
+func extendedFunctionEnv(fn *object.Function, args []object.Object) (*object.Environment, *object.Error) {
+	if len(args) < len(fn.Parameters) {
+		if fn.Name != "" {
+			return nil, newError("wrong number of arguments to `%s`. got=%d, want=%d",
+				fn.Name, len(args), len(fn.Parameters))
+		}
+		return nil, newError("wrong number of arguments. got=%d, want=%d",
+			len(args), len(fn.Parameters))
+	}
+
+	env := object.NewFunctionEnvironment(fn.Env)
 
 	for paramIdx, param := range fn.Parameters {
-		env.Set(param.Value, args[paramIdx])
+		if err := bindPattern(param, args[paramIdx], env); err != nil {
+			return nil, err
+		}
+	}
+
+	return env, nil
+}
+
+// bindPattern binds the identifiers of a let or parameter pattern to the
+// matching parts of value, failing if value does not have the pattern's shape.
+func bindPattern(pattern ast.Expression, value object.Object, env *object.Environment) *object.Error {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		env.Set(pattern.Value, value)
+		return nil
+	case *ast.ArrayPattern:
+		return bindArrayPattern(pattern, value, env)
+	case *ast.HashPattern:
+		return bindHashPattern(pattern, value, env)
+	default:
+		return newError("invalid binding pattern: %s", pattern.String())
+	}
+}
+
+func bindArrayPattern(pattern *ast.ArrayPattern, value object.Object, env *object.Environment) *object.Error {
+	array, ok := value.(*object.Array)
+	if tuple, isTuple := value.(*object.Tuple); isTuple {
+		array, ok = &object.Array{Elements: tuple.Elements}, true
+	}
+	if !ok {
+		return newError("cannot destructure %s with array pattern %s",
+			value.Type(), pattern.String())
+	}
+
+	want, got := len(pattern.Elements), len(array.Elements)
+	if pattern.Rest == nil && got != want {
+		return newError("array pattern %s expects %d elements, got %d",
+			pattern.String(), want, got)
+	}
+	if pattern.Rest != nil && got < want {
+		return newError("array pattern %s expects at least %d elements, got %d",
+			pattern.String(), want, got)
+	}
+
+	for i, element := range pattern.Elements {
+		if err := bindPattern(element, array.Elements[i], env); err != nil {
+			return err
+		}
+	}
+
+	if pattern.Rest != nil {
+		rest := make([]object.Object, got-want)
+		copy(rest, array.Elements[want:])
+		env.Set(pattern.Rest.Value, &object.Array{Elements: rest})
 	}
+	return nil
+}
 
-	return env
+func bindHashPattern(pattern *ast.HashPattern, value object.Object, env *object.Environment) *object.Error {
+	hash, ok := value.(*object.Hash)
+	if !ok {
+		return newError("cannot destructure %s with hash pattern %s",
+			value.Type(), pattern.String())
+	}
+
+	for i, key := range pattern.Keys {
+		pair, ok := hash.Get(&object.String{Value: key.Value})
+		if !ok {
+			return newError("hash pattern %s: key %q not found",
+				pattern.String(), key.Value)
+		}
+		if err := bindPattern(pattern.Values[i], pair.Value, env); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func unwarpReturnValue(obj object.Object) object.Object {
-	if returnValue, ok := obj.(*object.ReturnValue); ok {
-		return returnValue.Value
+	switch obj := obj.(type) {
+	case *object.ReturnValue:
+		return obj.Value
+	case *object.Break, *object.Continue:
+		return newError("%s outside loop", obj.Inspect())
 	}
 	return obj
 }
@@ -226,7 +476,7 @@ func evalExpression(exps []ast.Expression, env *object.Environment) []object.Obj
 	res := make([]object.Object, 0)
 	for _, exp := range exps {
 		evaluated := Eval(exp, env)
-		if isError(evaluated) {
+		if isAbrupt(evaluated) {
 			return []object.Object{evaluated}
 		}
 
@@ -236,49 +486,93 @@ func evalExpression(exps []ast.Expression, env *object.Environment) []object.Obj
 }
 
 func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
-	pairs := make(map[object.HashKey]object.HashPair)
+	hash := object.NewHash()
 
-	for keyNode, valueNode := range node.Pairs {
+	for _, keyNode := range node.Keys {
 		key := Eval(keyNode, env)
-		if isError(key) {
+		if isAbrupt(key) {
 			return key
 		}
 
-		hashKey, ok := key.(object.Hashable)
-		if !ok {
+		if !object.IsHashable(key) {
 			return newError("unusable as hash key: %s", key.Type())
 		}
 
-		value := Eval(valueNode, env)
-		if isError(value) {
+		value := Eval(node.Pairs[keyNode], env)
+		if isAbrupt(value) {
 			return value
 		}
 
-		hashed := hashKey.HashKey()
-		pairs[hashed] = object.HashPair{Key: key, Value: value}
+		hash.Set(key, value)
 	}
-	return &object.Hash{Pairs: pairs}
+	return hash
 }
 
 func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalStringIndexExpression(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
+	case left.Type() == object.RANGE_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalRangeIndexExpression(left, index)
+	case left.Type() == object.TUPLE_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalArrayIndexExpression(&object.Array{Elements: left.(*object.Tuple).Elements}, index)
 	default:
 		return newError("index operator not supported: %s", left.Type())
 	}
 }
 
+func evalRangeIndexExpression(left, index object.Object) object.Object {
+	rng := left.(*object.Range)
+
+	indexValue := index.(*object.Integer).Value
+	length := rng.Len()
+	if indexValue < 0 {
+		indexValue += length
+	}
+	if indexValue < 0 || indexValue >= length {
+		return NULL
+	}
+
+	return &object.Integer{Value: rng.At(indexValue)}
+}
+
+func evalRangeExpression(node *ast.RangeExpression, env *object.Environment) object.Object {
+	bounds := []ast.Expression{node.Start, node.End}
+	if node.Step != nil {
+		bounds = append(bounds, node.Step)
+	}
+
+	values := []int64{0, 0, 1}
+	for i, bound := range bounds {
+		value := Eval(bound, env)
+		if isAbrupt(value) {
+			return value
+		}
+		integer, ok := value.(*object.Integer)
+		if !ok {
+			return newError("range bounds must be INTEGER, got %s", value.Type())
+		}
+		values[i] = integer.Value
+	}
+
+	if values[2] == 0 {
+		return newError("range step cannot be zero")
+	}
+
+	return &object.Range{Start: values[0], End: values[1], Step: values[2], Inclusive: node.Inclusive}
+}
+
 func evalHashIndexExpression(hash, index object.Object) object.Object {
-	key, ok := index.(object.Hashable)
-	if !ok {
+	if !object.IsHashable(index) {
 		return newError("unusable as hash key: %s", index.Type())
 	}
 
 	hashObj := hash.(*object.Hash)
-	pair, ok := hashObj.Pairs[key.HashKey()]
+	pair, ok := hashObj.Get(index)
 	
 	if !ok {
 		return NULL
@@ -295,6 +589,10 @@ func evalArrayIndexExpression(left, index object.Object) object.Object {
 
 	indexValue := index.(*object.Integer).Value
 	maxIndex := int64(len(arrayObject.Elements) - 1)
+	if indexValue < 0 {
+		// negative indices count from the end
+		indexValue += maxIndex + 1
+	}
 	if indexValue < 0 || indexValue > maxIndex {
 		return NULL
 	}
@@ -302,6 +600,173 @@ func evalArrayIndexExpression(left, index object.Object) object.Object {
 	return arrayObject.Elements[indexValue]
 }
 
+// evalStringIndexExpression indexes a string by rune, not by byte.
+func evalStringIndexExpression(str, index object.Object) object.Object {
+	value := []rune(str.(*object.String).Value)
+
+	indexValue := index.(*object.Integer).Value
+	maxIndex := int64(len(value) - 1)
+	if indexValue < 0 {
+		indexValue += maxIndex + 1
+	}
+	if indexValue < 0 || indexValue > maxIndex {
+		return NULL
+	}
+
+	return &object.String{Value: string(value[indexValue])}
+}
+
+// evalChain evaluates a postfix chain of calls, indexes, slices and
+// property accesses. When an optional link such as a?.b finds a null a, the
+// rest of the chain is skipped and the whole chain is null, so a?.b.c and
+// a?.[0]() are null too; skipped reports that this happened.
+func evalChain(node ast.Expression, env *object.Environment) (result object.Object, skipped bool) {
+	var leftNode ast.Expression
+	optional := false
+	switch node := node.(type) {
+	case *ast.CallExpression:
+		leftNode = node.Function
+	case *ast.IndexExpression:
+		leftNode, optional = node.Left, node.Optional
+	case *ast.SliceExpression:
+		leftNode, optional = node.Left, node.Optional
+	case *ast.PropertyExpression:
+		leftNode, optional = node.Left, node.Optional
+	default:
+		return Eval(node, env), false
+	}
+
+	left, skipped := evalChain(leftNode, env)
+	if skipped || (optional && left == NULL) {
+		return NULL, true
+	}
+	if isAbrupt(left) {
+		return left, false
+	}
+
+	switch node := node.(type) {
+	case *ast.CallExpression:
+		args := evalExpression(node.Arguments, env)
+		if len(args) == 1 && isAbrupt(args[0]) {
+			return args[0], false
+		}
+		return applyFunction(left, args), false
+	case *ast.IndexExpression:
+		index := Eval(node.Index, env)
+		if isAbrupt(index) {
+			return index, false
+		}
+		return evalIndexExpression(left, index), false
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, left, env), false
+	default:
+		return getProperty(left, node.(*ast.PropertyExpression).Property.Value), false
+	}
+}
+
+func evalSliceExpression(node *ast.SliceExpression, left object.Object, env *object.Environment) object.Object {
+	bounds := []*int64{nil, nil, nil}
+	for i, exp := range []ast.Expression{node.Start, node.End, node.Step} {
+		if exp == nil {
+			continue
+		}
+		bound := Eval(exp, env)
+		if isAbrupt(bound) {
+			return bound
+		}
+		integer, ok := bound.(*object.Integer)
+		if !ok {
+			return newError("slice indices must be INTEGER, got %s", bound.Type())
+		}
+		bounds[i] = &integer.Value
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		indices, err := sliceIndices(int64(len(left.Elements)), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+		elements := make([]object.Object, 0, len(indices))
+		for _, i := range indices {
+			elements = append(elements, left.Elements[i])
+		}
+		return &object.Array{Elements: elements}
+	case *object.Tuple:
+		indices, err := sliceIndices(int64(len(left.Elements)), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+		elements := make([]object.Object, 0, len(indices))
+		for _, i := range indices {
+			elements = append(elements, left.Elements[i])
+		}
+		return &object.Tuple{Elements: elements}
+	case *object.String:
+		value := []rune(left.Value)
+		indices, err := sliceIndices(int64(len(value)), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+		out := make([]rune, 0, len(indices))
+		for _, i := range indices {
+			out = append(out, value[i])
+		}
+		return &object.String{Value: string(out)}
+	default:
+		return newError("slice operator not supported: %s", left.Type())
+	}
+}
+
+// sliceIndices returns the indices selected by [start:end:step] on a sequence
+// of the given length, following Python: negative bounds count from the end,
+// out of range bounds are clamped and a negative step walks backwards.
+func sliceIndices(length int64, start, end, step *int64) ([]int64, *object.Error) {
+	stepValue := int64(1)
+	if step != nil {
+		stepValue = *step
+	}
+	if stepValue == 0 {
+		return nil, newError("slice step cannot be zero")
+	}
+
+	// with a negative step the bounds are clamped to [-1, length-1]
+	lower, upper := int64(0), length
+	if stepValue < 0 {
+		lower, upper = -1, length-1
+	}
+
+	clamp := func(bound *int64, fallback int64) int64 {
+		if bound == nil {
+			return fallback
+		}
+		value := *bound
+		if value < 0 {
+			value += length
+		}
+		if value < lower {
+			return lower
+		}
+		if value > upper {
+			return upper
+		}
+		return value
+	}
+
+	var from, to int64
+	if stepValue > 0 {
+		from, to = clamp(start, lower), clamp(end, upper)
+	} else {
+		from, to = clamp(start, upper), clamp(end, lower)
+	}
+
+	indices := []int64{}
+	for i := from; (stepValue > 0 && i < to) || (stepValue < 0 && i > to); i += stepValue {
+		indices = append(indices, i)
+	}
+	return indices, nil
+}
+
 func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
 	if val, ok := env.Get(node.Value); ok {
 		return val
@@ -311,13 +776,185 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 		return builtin
 	}
 
+	if global, ok := stdlibGlobal(node.Value); ok {
+		return global
+	}
+
 	return newError("identifier not found: %s", node.Value)
 }
 
+func evalAssignExpression(node *ast.AssignExpression, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	if isAbrupt(val) {
+		return val
+	}
+
+	if node.Operator != "=" {
+		current, ok := env.Get(node.Name.Value)
+		if !ok {
+			return newError("identifier not found: %s", node.Name.Value)
+		}
+		// x += y is x = x + y
+		operator := node.Operator[:len(node.Operator)-1]
+		val = evalInfixExpression(operator, current, val)
+		if isError(val) {
+			return val
+		}
+	}
+
+	if _, ok := env.Assign(node.Name.Value, val); !ok {
+		return newError("identifier not found: %s", node.Name.Value)
+	}
+	return val
+}
+
+func evalWhileStatement(ws *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		condition := Eval(ws.Condition, env)
+		if isAbrupt(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return NULL
+		}
+
+		if result, done := evalLoopBody(ws.Body, object.NewEnclosedEnvironment(env)); done {
+			return result
+		}
+	}
+}
+
+func evalForStatement(fs *ast.ForStatement, env *object.Environment) object.Object {
+	// bindings made by the init statement only live as long as the loop
+	loopEnv := object.NewEnclosedEnvironment(env)
+
+	if fs.Init != nil {
+		init := Eval(fs.Init, loopEnv)
+		if isAbrupt(init) {
+			return init
+		}
+	}
+
+	for {
+		if fs.Condition != nil {
+			condition := Eval(fs.Condition, loopEnv)
+			if isAbrupt(condition) {
+				return condition
+			}
+			if !isTruthy(condition) {
+				return NULL
+			}
+		}
+
+		if result, done := evalLoopBody(fs.Body, object.NewEnclosedEnvironment(loopEnv)); done {
+			return result
+		}
+
+		// the next iteration gets its own copy of the init bindings, so
+		// closures made in this one keep this iteration's values
+		loopEnv = loopEnv.Copy()
+		if fs.Post != nil {
+			post := Eval(fs.Post, loopEnv)
+			if isAbrupt(post) {
+				return post
+			}
+		}
+	}
+}
+
+func evalForInStatement(fs *ast.ForInStatement, env *object.Environment) object.Object {
+	iterable := Eval(fs.Iterable, env)
+	if isAbrupt(iterable) {
+		return iterable
+	}
+
+	iter, ok := newIterator(iterable)
+	if !ok {
+		return newError("not iterable: %s", iterable.Type())
+	}
+
+	// a single loop variable over a hash binds the key, otherwise the element
+	_, keyOnly := iterable.(*object.Hash)
+	keyOnly = keyOnly && fs.Key == nil
+
+	for {
+		key, value, ok := iter.Next()
+		if !ok {
+			return NULL
+		}
+		if isError(value) {
+			return value
+		}
+
+		// every iteration gets its own scope so closures capture its values
+		iterEnv := object.NewEnclosedEnvironment(env)
+		switch {
+		case keyOnly:
+			iterEnv.Set(fs.Value.Value, key)
+		case fs.Key != nil:
+			iterEnv.Set(fs.Key.Value, key)
+			iterEnv.Set(fs.Value.Value, value)
+		default:
+			iterEnv.Set(fs.Value.Value, value)
+		}
+
+		if result, done := evalLoopBody(fs.Body, iterEnv); done {
+			return result
+		}
+	}
+}
+
+// newIterator returns an iterator over any iterable object. Functions are
+// iterated by calling them with no arguments until they return null, or
+// nothing at all as an empty body does.
+func newIterator(obj object.Object) (object.Iterator, bool) {
+	switch obj := obj.(type) {
+	case object.Iterable:
+		return obj.Iterator(), true
+	case *object.Function, *object.Builtin:
+		return &functionIterator{fn: obj}, true
+	}
+	return nil, false
+}
+
+type functionIterator struct {
+	fn    object.Object
+	index int64
+}
+
+func (it *functionIterator) Next() (object.Object, object.Object, bool) {
+	value := applyFunction(it.fn, []object.Object{})
+	if value == nil || value == NULL {
+		return nil, nil, false
+	}
+	key := &object.Integer{Value: it.index}
+	it.index++
+	return key, value, true
+}
+
+// evalLoopBody runs one iteration of a loop body. It reports done when the
+// loop has to stop, together with the value the loop statement yields. Every
+// loop runs each iteration of its body in a fresh scope enclosed by env, so
+// bindings made in the body do not outlive the iteration.
+func evalLoopBody(body *ast.BlockStatement, env *object.Environment) (object.Object, bool) {
+	result := Eval(body, env)
+	if result == nil {
+		return nil, false
+	}
+
+	switch result.Type() {
+	case object.BREAK_OBJ:
+		return NULL, true
+	case object.RETURN_VALUE_OBJ, object.ERROR_OBJ:
+		return result, true
+	}
+	return nil, false
+}
+
 func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
 	condition := Eval(ie.Condition, env)
 	// fmt.Printf("condition is %+v\n", isTruthy(condition))
-	if isError(condition) {
+	if isAbrupt(condition) {
 		return condition
 	}
 
@@ -330,6 +967,126 @@ func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Obje
 	}
 }
 
+func evalConditionalExpression(ce *ast.ConditionalExpression, env *object.Environment) object.Object {
+	condition := Eval(ce.Condition, env)
+	if isAbrupt(condition) {
+		return condition
+	}
+
+	if isTruthy(condition) {
+		return Eval(ce.Consequence, env)
+	}
+	return Eval(ce.Alternative, env)
+}
+
+func evalMatchExpression(me *ast.MatchExpression, env *object.Environment) object.Object {
+	subject := Eval(me.Subject, env)
+	if isAbrupt(subject) {
+		return subject
+	}
+
+	for _, arm := range me.Arms {
+		for _, pattern := range arm.Patterns {
+			// bindings made by a pattern are only visible inside its arm
+			armEnv := object.NewEnclosedEnvironment(env)
+			matched, err := matchPattern(pattern, subject, armEnv)
+			if err != nil {
+				return err
+			}
+			if !matched {
+				continue
+			}
+
+			if arm.Guard != nil {
+				guard := Eval(arm.Guard, armEnv)
+				if isAbrupt(guard) {
+					return guard
+				}
+				if !isTruthy(guard) {
+					continue
+				}
+			}
+
+			return Eval(arm.Body, armEnv)
+		}
+	}
+
+	return NULL
+}
+
+// matchPattern reports whether value matches pattern, binding identifiers
+// of the pattern in env. `_` matches anything without binding, array and hash
+// literals match structurally and any other expression matches a value equal
+// to its result.
+func matchPattern(pattern ast.Expression, value object.Object, env *object.Environment) (bool, object.Object) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value != "_" {
+			env.Set(pattern.Value, value)
+		}
+		return true, nil
+	case *ast.ArrayLiteral:
+		// like an array pattern in let, this destructures tuples too
+		switch value := value.(type) {
+		case *object.Array:
+			return matchElements(pattern.Elements, value.Elements, env)
+		case *object.Tuple:
+			return matchElements(pattern.Elements, value.Elements, env)
+		}
+		return false, nil
+	case *ast.TupleLiteral:
+		tuple, ok := value.(*object.Tuple)
+		if !ok {
+			return false, nil
+		}
+		return matchElements(pattern.Elements, tuple.Elements, env)
+	case *ast.HashLiteral:
+		hash, ok := value.(*object.Hash)
+		if !ok {
+			return false, nil
+		}
+		for _, keyNode := range pattern.Keys {
+			key := Eval(keyNode, env)
+			if isAbrupt(key) {
+				return false, key
+			}
+			if !object.IsHashable(key) {
+				return false, newError("unusable as hash key: %s", key.Type())
+			}
+			pair, ok := hash.Get(key)
+			if !ok {
+				return false, nil
+			}
+			matched, err := matchPattern(pattern.Pairs[keyNode], pair.Value, env)
+			if err != nil || !matched {
+				return false, err
+			}
+		}
+		return true, nil
+	default:
+		expected := Eval(pattern, env)
+		if isAbrupt(expected) {
+			return false, expected
+		}
+		return object.Equals(expected, value), nil
+	}
+}
+
+// matchElements matches the element patterns against the elements of an
+// array or tuple of the same length.
+func matchElements(patterns []ast.Expression, elements []object.Object, env *object.Environment) (bool, object.Object) {
+	if len(elements) != len(patterns) {
+		return false, nil
+	}
+	for i, element := range patterns {
+		matched, err := matchPattern(element, elements[i], env)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
 func evalPrefixExpression(operator string, right object.Object) object.Object {
 	switch operator {
 	case "!":
@@ -343,14 +1100,31 @@ func evalPrefixExpression(operator string, right object.Object) object.Object {
 
 func evalInfixExpression(operator string, left, right object.Object) object.Object {
 	switch {
+	case operator == "??":
+		if left == NULL {
+			return right
+		}
+		return left
+	case operator == "in":
+		return evalInExpression(left, right)
+	case operator == "not in":
+		result := evalInExpression(left, right)
+		if isError(result) {
+			return result
+		}
+		return nativeBoolToBooleanObject(result != TRUE)
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
-	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
-		return evalStringInfixExpression(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(operator, left, right)
 	case operator == "==":
-		return nativeBoolToBooleanObject(left == right)
+		return nativeBoolToBooleanObject(object.Equals(left, right))
 	case operator == "!=":
-		return nativeBoolToBooleanObject(left != right)
+		return nativeBoolToBooleanObject(!object.Equals(left, right))
+	case isComparisonOperator(operator):
+		return evalComparisonExpression(operator, left, right)
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return evalStringInfixExpression(operator, left, right)
 	case left.Type() != right.Type():
 		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
 	default:
@@ -358,6 +1132,105 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 	}
 }
 
+// evalPropagateExpression unwraps an ok result. An err result is returned
+// from the enclosing function, the same way a return statement would; outside
+// a function there is nothing to return it from, so it is an error.
+func evalPropagateExpression(node *ast.PropagateExpression, env *object.Environment) object.Object {
+	value := Eval(node.Value, env)
+	if isAbrupt(value) {
+		return value
+	}
+
+	result, ok := value.(*object.Result)
+	if !ok {
+		return newError("operand of `?` must be RESULT, got %s", value.Type())
+	}
+	if !result.Ok {
+		if !env.InFunction() {
+			return newError("cannot propagate %s with `?` outside a function", result.Inspect())
+		}
+		return &object.ReturnValue{Value: result}
+	}
+	return result.Value
+}
+
+func isComparisonOperator(operator string) bool {
+	switch operator {
+	case "<", ">", "<=", ">=":
+		return true
+	}
+	return false
+}
+
+// evalComparisonExpression orders left and right with object.Compare.
+func evalComparisonExpression(operator string, left, right object.Object) object.Object {
+	result, ok := object.Compare(left, right)
+	if !ok {
+		if left.Type() != right.Type() {
+			return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+		}
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+
+	switch operator {
+	case "<":
+		return nativeBoolToBooleanObject(result < 0)
+	case ">":
+		return nativeBoolToBooleanObject(result > 0)
+	case "<=":
+		return nativeBoolToBooleanObject(result <= 0)
+	default:
+		return nativeBoolToBooleanObject(result >= 0)
+	}
+}
+
+// evalInExpression evaluates `left in right`, the membership test. Hashes
+// are searched by key, arrays and tuples by element equality, strings by
+// substring and ranges by value. A left operand of a type the container
+// cannot hold, such as an integer in a string or a string in a range, is an
+// error for every container.
+func evalInExpression(left, right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Hash:
+		if !object.IsHashable(left) {
+			return newError("unusable as hash key: %s", left.Type())
+		}
+		_, ok := right.Get(left)
+		return nativeBoolToBooleanObject(ok)
+	case *object.Array:
+		return nativeBoolToBooleanObject(containsElement(right.Elements, left))
+	case *object.Tuple:
+		return nativeBoolToBooleanObject(containsElement(right.Elements, left))
+	case *object.String:
+		substr, ok := left.(*object.String)
+		if !ok {
+			return newError("unknown operator: %s in %s", left.Type(), right.Type())
+		}
+		return nativeBoolToBooleanObject(strings.Contains(right.Value, substr.Value))
+	case *object.Range:
+		switch value := left.(type) {
+		case *object.Integer:
+			return nativeBoolToBooleanObject(right.Contains(value.Value))
+		case *object.Float:
+			whole := value.Value == math.Trunc(value.Value) &&
+				value.Value >= math.MinInt64 && value.Value < math.MaxInt64
+			return nativeBoolToBooleanObject(whole && right.Contains(int64(value.Value)))
+		}
+		return newError("unknown operator: %s in %s", left.Type(), right.Type())
+	default:
+		return newError("unknown operator: %s in %s", left.Type(), right.Type())
+	}
+}
+
+func containsElement(elements []object.Object, value object.Object) bool {
+	for _, el := range elements {
+		if object.Equals(value, el) {
+			return true
+		}
+	}
+	return false
+}
+
 func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
 	if operator != "+" {
 		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
@@ -385,6 +1258,42 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case ">":
 		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalFloatInfixExpression evaluates arithmetic and comparisons between
+// floats, or between a float and an integer, which is converted to a float.
+func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
+
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		return &object.Float{Value: leftVal / rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
 	case "==":
 		return nativeBoolToBooleanObject(leftVal == rightVal)
 	case "!=":
@@ -394,6 +1303,17 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	}
 }
 
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+func toFloat(obj object.Object) float64 {
+	if integer, ok := obj.(*object.Integer); ok {
+		return float64(integer.Value)
+	}
+	return obj.(*object.Float).Value
+}
+
 func evalBangOperatorExpression(right object.Object) object.Object {
 	switch right {
 	case TRUE:
@@ -408,6 +1328,9 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
+	if float, ok := right.(*object.Float); ok {
+		return &object.Float{Value: -float.Value}
+	}
 	if right.Type() != object.INTEGER_OBJ {
 		return newError("unknown operator: -%s", right.Type())
 	}
@@ -425,6 +1348,8 @@ func nativeBoolToBooleanObject(input bool) *object.Boolean {
 func evalStatements(stmts []ast.Statement, env *object.Environment) object.Object {
 	var result object.Object
 
+	hoistFunctions(stmts, env)
+
 	for _, statement := range stmts {
 		result = Eval(statement, env)
 
@@ -433,6 +1358,8 @@ func evalStatements(stmts []ast.Statement, env *object.Environment) object.Objec
 			return result.Value
 		case *object.Error:
 			return result
+		case *object.Break, *object.Continue:
+			return newError("%s outside loop", result.Inspect())
 		}
 	}
 
@@ -441,11 +1368,14 @@ func evalStatements(stmts []ast.Statement, env *object.Environment) object.Objec
 
 func evalBlockStatments(stmts []ast.Statement, env *object.Environment) object.Object {
 	var result object.Object
+
+	hoistFunctions(stmts, env)
 	for _, statement := range stmts {
 		result = Eval(statement, env)
 		if result != nil {
 			rt := result.Type()
-			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ ||
+				rt == object.BREAK_OBJ || rt == object.CONTINUE_OBJ {
 				return result
 			}
 		}
@@ -453,6 +1383,20 @@ func evalBlockStatments(stmts []ast.Statement, env *object.Environment) object.O
 	return result
 }
 
+// hoistFunctions binds the functions declared by fn statements in stmts, so
+// they can be called, or call each other, before their declaration is reached.
+// Reaching the declaration later does not bind the name again.
+func hoistFunctions(stmts []ast.Statement, env *object.Environment) {
+	for _, statement := range stmts {
+		if export, ok := statement.(*ast.ExportStatement); ok {
+			statement = export.Statement
+		}
+		if fs, ok := statement.(*ast.FunctionStatement); ok {
+			env.Set(fs.Name.Value, Eval(fs.Function, env))
+		}
+	}
+}
+
 func isTruthy(obj object.Object) bool {
 	switch obj {
 	case NULL:
@@ -471,4 +1415,15 @@ func isError(obj object.Object) bool {
 		return obj.Type() == object.ERROR_OBJ
 	}
 	return false
+}
+
+// isAbrupt reports whether obj, the result of evaluating a sub-expression,
+// aborts the evaluation of the enclosing expression. Besides errors this
+// includes the return value produced by `result?`, which can appear in the
+// middle of an expression.
+func isAbrupt(obj object.Object) bool {
+	if obj != nil {
+		return obj.Type() == object.ERROR_OBJ || obj.Type() == object.RETURN_VALUE_OBJ
+	}
+	return false
 }
\ No newline at end of file
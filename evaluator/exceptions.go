@@ -0,0 +1,116 @@
+package evaluator
+
+import (
+	"monkey/ast"
+	"monkey/object"
+)
+
+// runtimeErrorKind is the type reported to catch blocks for errors raised by
+// the interpreter itself, such as type mismatches or bad builtin arguments.
+const runtimeErrorKind = "RuntimeError"
+
+func evalThrowStatement(node *ast.ThrowStatement, env *object.Environment) object.Object {
+	value := Eval(node.Value, env)
+	if isAbrupt(value) {
+		return value
+	}
+
+	return newThrownError(value)
+}
+
+// newThrownError wraps a thrown value in an error. Throwing a hash with
+// string "message" and "type" fields, such as a caught error, keeps them.
+func newThrownError(value object.Object) *object.Error {
+	err := &object.Error{Message: value.Inspect(), Kind: "Error", Value: value}
+
+	switch value := value.(type) {
+	case *object.String:
+		err.Message = value.Value
+	case *object.Hash:
+		if message, ok := hashStringField(value, "message"); ok {
+			err.Message = message
+		}
+		if kind, ok := hashStringField(value, "type"); ok {
+			err.Kind = kind
+		}
+	}
+
+	return err
+}
+
+func hashStringField(hash *object.Hash, name string) (string, bool) {
+	pair, ok := hash.Get(&object.String{Value: name})
+	if !ok {
+		return "", false
+	}
+	str, ok := pair.Value.(*object.String)
+	if !ok {
+		return "", false
+	}
+	return str.Value, true
+}
+
+// evalTryExpression runs the try block and, if it fails, the catch block.
+// The finally block always runs; its result is discarded unless it fails or
+// leaves the function or loop itself, in which case that takes over.
+func evalTryExpression(node *ast.TryExpression, env *object.Environment) object.Object {
+	result := Eval(node.Block, env)
+
+	if err, ok := result.(*object.Error); ok && node.Catch != nil {
+		catchEnv := object.NewEnclosedEnvironment(env)
+		if node.CatchParam != nil {
+			catchEnv.Set(node.CatchParam.Value, errorToHash(err))
+		}
+		result = Eval(node.Catch, catchEnv)
+	}
+
+	if node.Finally != nil {
+		finally := Eval(node.Finally, env)
+		if finally != nil {
+			switch finally.Type() {
+			case object.ERROR_OBJ, object.RETURN_VALUE_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ:
+				return finally
+			}
+		}
+	}
+
+	if result == nil {
+		return NULL
+	}
+	return result
+}
+
+// errorToHash is the value a catch block sees: a hash with the message,
+// type and stack of the error, and the thrown value (null for runtime errors).
+func errorToHash(err *object.Error) *object.Hash {
+	kind := err.Kind
+	if kind == "" {
+		kind = runtimeErrorKind
+	}
+
+	stack := make([]object.Object, len(err.Stack))
+	for i, name := range err.Stack {
+		stack[i] = &object.String{Value: name}
+	}
+
+	value := err.Value
+	if value == nil {
+		value = NULL
+	}
+
+	fields := []struct {
+		name  string
+		value object.Object
+	}{
+		{"message", &object.String{Value: err.Message}},
+		{"type", &object.String{Value: kind}},
+		{"stack", &object.Array{Elements: stack}},
+		{"value", value},
+	}
+
+	hash := object.NewHash()
+	for _, field := range fields {
+		hash.Set(&object.String{Value: field.name}, field.value)
+	}
+	return hash
+}
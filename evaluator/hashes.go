@@ -0,0 +1,192 @@
+package evaluator
+
+import "monkey/object"
+
+func init() {
+	for name, fn := range map[string]object.BuiltinFunction{
+		"keys":         hashKeys,
+		"values":       hashValues,
+		"entries":      hashEntries,
+		"has":          hashHas,
+		"delete":       hashDelete,
+		"merge":        hashMerge,
+		"get":          hashGet,
+		"from_entries": hashFromEntries,
+	} {
+		builtins[name] = &object.Builtin{Name: name, Fn: fn}
+	}
+}
+
+func hashArgument(name string, arg object.Object) (*object.Hash, *object.Error) {
+	hash, ok := arg.(*object.Hash)
+	if !ok {
+		return nil, newError("argument to `%s` must be HASH, got %s", name, arg.Type())
+	}
+	return hash, nil
+}
+
+// checkHashKey reports an error for keys that cannot be stored in a hash.
+func checkHashKey(key object.Object) *object.Error {
+	if !object.IsHashable(key) {
+		return newError("unusable as hash key: %s", key.Type())
+	}
+	return nil
+}
+
+func hashKeys(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	hash, err := hashArgument("keys", args[0])
+	if err != nil {
+		return err
+	}
+
+	pairs := hash.Entries()
+	keys := make([]object.Object, len(pairs))
+	for i, pair := range pairs {
+		keys[i] = pair.Key
+	}
+	return &object.Array{Elements: keys}
+}
+
+func hashValues(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	hash, err := hashArgument("values", args[0])
+	if err != nil {
+		return err
+	}
+
+	pairs := hash.Entries()
+	values := make([]object.Object, len(pairs))
+	for i, pair := range pairs {
+		values[i] = pair.Value
+	}
+	return &object.Array{Elements: values}
+}
+
+// hashEntries returns the pairs of a hash as [key, value] arrays.
+func hashEntries(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	hash, err := hashArgument("entries", args[0])
+	if err != nil {
+		return err
+	}
+
+	pairs := hash.Entries()
+	entries := make([]object.Object, len(pairs))
+	for i, pair := range pairs {
+		entries[i] = &object.Array{Elements: []object.Object{pair.Key, pair.Value}}
+	}
+	return &object.Array{Elements: entries}
+}
+
+func hashHas(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	hash, err := hashArgument("has", args[0])
+	if err != nil {
+		return err
+	}
+	if err := checkHashKey(args[1]); err != nil {
+		return err
+	}
+
+	_, ok := hash.Get(args[1])
+	return nativeBoolToBooleanObject(ok)
+}
+
+// hashDelete returns a copy of the hash without the given keys; the hash
+// itself is left unchanged.
+func hashDelete(args ...object.Object) object.Object {
+	if len(args) < 2 {
+		return newError("wrong number of arguments. got=%d, want at least 2", len(args))
+	}
+	hash, err := hashArgument("delete", args[0])
+	if err != nil {
+		return err
+	}
+
+	result := hash.Copy()
+	for _, key := range args[1:] {
+		if err := checkHashKey(key); err != nil {
+			return err
+		}
+		result.Delete(key)
+	}
+	return result
+}
+
+// hashMerge returns a new hash with the pairs of all its arguments. When
+// several hashes share a key, the last value wins but the key keeps its
+// first position.
+func hashMerge(args ...object.Object) object.Object {
+	if len(args) < 1 {
+		return newError("wrong number of arguments. got=%d, want at least 1", len(args))
+	}
+
+	result := object.NewHash()
+	for _, arg := range args {
+		hash, err := hashArgument("merge", arg)
+		if err != nil {
+			return err
+		}
+		for _, pair := range hash.Entries() {
+			result.Set(pair.Key, pair.Value)
+		}
+	}
+	return result
+}
+
+// hashGet returns the value stored under a key, or the default (null if
+// none is given) when the key is missing.
+func hashGet(args ...object.Object) object.Object {
+	if len(args) != 2 && len(args) != 3 {
+		return newError("wrong number of arguments. got=%d, want=2 or 3", len(args))
+	}
+	hash, err := hashArgument("get", args[0])
+	if err != nil {
+		return err
+	}
+	if err := checkHashKey(args[1]); err != nil {
+		return err
+	}
+
+	if pair, ok := hash.Get(args[1]); ok {
+		return pair.Value
+	}
+	if len(args) == 3 {
+		return args[2]
+	}
+	return NULL
+}
+
+// hashFromEntries builds a hash from [key, value] arrays, the inverse of
+// entries.
+func hashFromEntries(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	entries, err := arrayArgument("from_entries", args[0])
+	if err != nil {
+		return err
+	}
+
+	result := object.NewHash()
+	for _, entry := range entries {
+		pair, ok := entry.(*object.Array)
+		if !ok || len(pair.Elements) != 2 {
+			return newError("entry of `from_entries` must be a [key, value] ARRAY, got %s", entry.Inspect())
+		}
+		if err := checkHashKey(pair.Elements[0]); err != nil {
+			return err
+		}
+		result.Set(pair.Elements[0], pair.Elements[1])
+	}
+	return result
+}
@@ -0,0 +1,174 @@
+package evaluator
+
+import "monkey/object"
+
+// methods holds the methods available on each object type through
+// value.name(args). A method is a builtin that receives the value it was
+// called on as its first argument.
+var methods map[object.ObjectType]map[string]*object.Builtin
+
+func init() {
+	methods = map[object.ObjectType]map[string]*object.Builtin{
+		object.STRING_OBJ: {
+			"len":         builtins["len"],
+			"bytes":       builtins["bytes"],
+			"runes":       builtins["runes"],
+			"split":       {Fn: stringSplit},
+			"trim":        {Fn: stringTrim},
+			"trim_left":   {Fn: stringTrimLeft},
+			"trim_right":  {Fn: stringTrimRight},
+			"upper":       {Fn: stringUpper},
+			"lower":       {Fn: stringLower},
+			"replace":     {Fn: stringReplace},
+			"contains":    {Fn: stringContains},
+			"starts_with": {Fn: stringStartsWith},
+			"ends_with":   {Fn: stringEndsWith},
+			"index_of":    {Fn: stringIndexOf},
+			"repeat":      {Fn: stringRepeat},
+			"pad_left":    {Fn: stringPadLeft},
+			"pad_right":   {Fn: stringPadRight},
+			"chars":       {Fn: stringChars},
+			"format":      {Fn: stringFormat},
+		},
+		object.ARRAY_OBJ: {
+			"len":      builtins["len"],
+			"first":    builtins["first"],
+			"last":     builtins["last"],
+			"rest":     builtins["rest"],
+			"push":     builtins["push"],
+			"join":     {Fn: stringJoin},
+			"map":      {Fn: arrayMap},
+			"filter":   {Fn: arrayFilter},
+			"reduce":   {Fn: arrayReduce},
+			"each":     {Fn: arrayEach},
+			"find":     {Fn: arrayFind},
+			"any":      {Fn: arrayAny},
+			"all":      {Fn: arrayAll},
+			"zip":      {Fn: arrayZip},
+			"flatten":  {Fn: arrayFlatten},
+			"sort":     {Fn: arraySort},
+			"reverse":  {Fn: arrayReverse},
+			"index_of": {Fn: arrayIndexOf},
+			"concat":   {Fn: arrayConcat},
+			"unique":   {Fn: arrayUnique},
+		},
+		object.HASH_OBJ: {
+			"len":     builtins["len"],
+			"keys":    {Fn: hashKeys},
+			"values":  {Fn: hashValues},
+			"entries": {Fn: hashEntries},
+			"has":     {Fn: hashHas},
+			"delete":  {Fn: hashDelete},
+			"merge":   {Fn: hashMerge},
+			"get":     {Fn: hashGet},
+		},
+		object.RESULT_OBJ: {
+			"is_ok":     {Fn: resultIsOk},
+			"is_err":    {Fn: resultIsErr},
+			"unwrap":    {Fn: resultUnwrap},
+			"unwrap_or": {Fn: resultUnwrapOr},
+		},
+		object.TUPLE_OBJ: {
+			"len":      builtins["len"],
+			"to_array": builtins["to_array"],
+			"index_of": {Fn: arrayIndexOf},
+		},
+		object.RANGE_OBJ: {
+			"len":      builtins["len"],
+			"to_array": builtins["to_array"],
+		},
+	}
+}
+
+// getProperty resolves value.name. Hash fields take precedence over methods,
+// so a hash of functions can be called like an object; on a module it reads
+// an export.
+func getProperty(obj object.Object, name string) object.Object {
+	if module, ok := obj.(*object.Module); ok {
+		if value, ok := module.Exports[name]; ok {
+			return value
+		}
+		return newError("module %s has no export %s", module.Path, name)
+	}
+
+	hash, isHash := obj.(*object.Hash)
+	if isHash {
+		if pair, ok := hash.Get(&object.String{Value: name}); ok {
+			return pair.Value
+		}
+	}
+
+	if method, ok := methods[obj.Type()][name]; ok {
+		return bindMethod(obj, name, method)
+	}
+
+	if isHash {
+		return NULL
+	}
+	return newError("no method %s on %s", name, obj.Type())
+}
+
+// bindMethod returns a builtin that calls method with receiver prepended
+// to its arguments.
+func bindMethod(receiver object.Object, name string, method *object.Builtin) *object.Builtin {
+	return &object.Builtin{
+		Name: string(receiver.Type()) + "." + name,
+		Fn: func(args ...object.Object) object.Object {
+			return method.Fn(append([]object.Object{receiver}, args...)...)
+		},
+	}
+}
+
+func resultIsOk(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	if args[0].Type() != object.RESULT_OBJ {
+		return newError("argument to `is_ok` must be RESULT, got %s", args[0].Type())
+	}
+
+	return nativeBoolToBooleanObject(args[0].(*object.Result).Ok)
+}
+
+func resultIsErr(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	if args[0].Type() != object.RESULT_OBJ {
+		return newError("argument to `is_err` must be RESULT, got %s", args[0].Type())
+	}
+
+	return nativeBoolToBooleanObject(!args[0].(*object.Result).Ok)
+}
+
+// resultUnwrap returns the value of an ok result and fails on an err, so a
+// surrounding try can catch it.
+func resultUnwrap(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	if args[0].Type() != object.RESULT_OBJ {
+		return newError("argument to `unwrap` must be RESULT, got %s", args[0].Type())
+	}
+
+	result := args[0].(*object.Result)
+	if !result.Ok {
+		return newError("unwrap called on %s", result.Inspect())
+	}
+	return result.Value
+}
+
+func resultUnwrapOr(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	if args[0].Type() != object.RESULT_OBJ {
+		return newError("argument to `unwrap_or` must be RESULT, got %s", args[0].Type())
+	}
+
+	result := args[0].(*object.Result)
+	if !result.Ok {
+		return args[1]
+	}
+	return result.Value
+}
@@ -0,0 +1,201 @@
+package evaluator
+
+import (
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parse"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SearchPath lists the directories searched, in order, for an import that
+// is not found next to the importing file. Paths starting with ./ or ../
+// are only resolved relative to the importing file.
+var SearchPath []string
+
+var (
+	// moduleCache maps resolved paths to loaded modules, so each module is
+	// evaluated once however often it is imported.
+	moduleCache = map[string]*object.Module{}
+
+	// loadingModules holds the modules being loaded, outermost first.
+	loadingModules []string
+)
+
+// ResetModules forgets every loaded module, so that the next import of a
+// module evaluates it afresh. Each program run should start with it.
+func ResetModules() {
+	moduleCache = map[string]*object.Module{}
+	loadingModules = nil
+}
+
+func evalImportStatement(node *ast.ImportStatement, env *object.Environment) object.Object {
+	imported := importModule(node.Path, env.File())
+	if isError(imported) {
+		return imported
+	}
+	module := imported.(*object.Module)
+
+	if node.Alias != nil {
+		return env.Set(node.Alias.Value, module)
+	}
+
+	for _, name := range node.Names {
+		value, ok := module.Exports[name.Value]
+		if !ok {
+			return newError("module %s has no export %s", node.Path, name.Value)
+		}
+		env.Set(name.Value, value)
+	}
+	return module
+}
+
+func importModule(path, importer string) object.Object {
+	resolved, ok := resolveModule(path, importer)
+	if !ok {
+		return newError("module not found: %s", path)
+	}
+
+	return loadModuleOnce(resolved, func() object.Object {
+		return loadModule(resolved)
+	})
+}
+
+// loadModuleOnce returns the cached module for key, or calls load to
+// evaluate it, failing if the module is already being loaded further up
+// the import chain.
+func loadModuleOnce(key string, load func() object.Object) object.Object {
+	if module, ok := moduleCache[key]; ok {
+		return module
+	}
+
+	for i, loading := range loadingModules {
+		if loading == key {
+			cycle := append(append([]string{}, loadingModules[i:]...), key)
+			return newError("import cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	loadingModules = append(loadingModules, key)
+	defer func() { loadingModules = loadingModules[:len(loadingModules)-1] }()
+
+	return load()
+}
+
+// resolveModule finds the file an import path refers to and returns its
+// absolute path.
+func resolveModule(path, importer string) (string, bool) {
+	candidates := []string{path}
+	if !filepath.IsAbs(path) {
+		dir := "."
+		if importer != "" {
+			dir = filepath.Dir(importer)
+		}
+		candidates = []string{filepath.Join(dir, path)}
+
+		if !strings.HasPrefix(path, "./") && !strings.HasPrefix(path, "../") {
+			for _, dir := range SearchPath {
+				candidates = append(candidates, filepath.Join(dir, path))
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		info, err := os.Stat(candidate)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if abs, err := filepath.Abs(candidate); err == nil {
+			return abs, true
+		}
+		return candidate, true
+	}
+	return "", false
+}
+
+func loadModule(path string) object.Object {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return newError("cannot read module %s: %s", path, err)
+	}
+
+	return evalModule(path, string(source))
+}
+
+// evalModule evaluates the source of the module at path in an environment of
+// its own and collects the names bound by its top-level export statements.
+func evalModule(path, source string) object.Object {
+	p := parse.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return newError("parse errors in module %s: %s", path, strings.Join(p.Errors(), "; "))
+	}
+
+	env := object.NewEnvironment()
+	env.SetFile(path)
+
+	result := Eval(program, env)
+	if isError(result) {
+		return result
+	}
+
+	module := &object.Module{Path: path, Exports: map[string]object.Object{}}
+	for _, name := range exportedNames(program) {
+		if value, ok := env.Get(name); ok {
+			module.Exports[name] = value
+		}
+	}
+
+	moduleCache[path] = module
+	return module
+}
+
+// exportedNames returns the names bound by the top-level export statements
+// of program.
+func exportedNames(program *ast.Program) []string {
+	names := []string{}
+	for _, statement := range program.Statements {
+		if export, ok := statement.(*ast.ExportStatement); ok {
+			names = append(names, declaredNames(export.Statement)...)
+		}
+	}
+	return names
+}
+
+// declaredNames returns the names bound by a let or fn statement.
+func declaredNames(stmt ast.Statement) []string {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		if stmt.Pattern != nil {
+			return patternNames(stmt.Pattern)
+		}
+		return []string{stmt.Name.Value}
+	case *ast.FunctionStatement:
+		return []string{stmt.Name.Value}
+	}
+	return nil
+}
+
+func patternNames(pattern ast.Expression) []string {
+	names := []string{}
+
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		names = append(names, pattern.Value)
+	case *ast.ArrayPattern:
+		for _, el := range pattern.Elements {
+			names = append(names, patternNames(el)...)
+		}
+		if pattern.Rest != nil {
+			names = append(names, pattern.Rest.Value)
+		}
+	case *ast.HashPattern:
+		for _, value := range pattern.Values {
+			names = append(names, patternNames(value)...)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,77 @@
+package evaluator
+
+import (
+	"embed"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parse"
+	"path"
+	"strings"
+)
+
+// The standard library is written in Monkey. The names exported by its
+// modules are globals: a module is evaluated the first time one of its
+// names is referenced and not otherwise bound.
+//
+//go:embed stdlib/*.mk
+var stdlibFS embed.FS
+
+// stdlibIndex maps each global defined by the standard library to the
+// module that exports it. It is built on first use.
+var stdlibIndex map[string]string
+
+func stdlibGlobal(name string) (object.Object, bool) {
+	if stdlibIndex == nil {
+		stdlibIndex = indexStdlib()
+	}
+
+	file, ok := stdlibIndex[name]
+	if !ok {
+		return nil, false
+	}
+
+	key := path.Join("std", path.Base(file))
+	loaded := loadModuleOnce(key, func() object.Object {
+		source, err := stdlibFS.ReadFile(file)
+		if err != nil {
+			return newError("cannot read module %s: %s", key, err)
+		}
+		return evalModule(key, string(source))
+	})
+	if isError(loaded) {
+		return loaded, true
+	}
+
+	return loaded.(*object.Module).Exports[name], true
+}
+
+// indexStdlib parses the standard library modules to find what they export.
+// The modules are embedded, so a parse error is a bug in the interpreter.
+func indexStdlib() map[string]string {
+	index := map[string]string{}
+
+	entries, err := stdlibFS.ReadDir("stdlib")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, entry := range entries {
+		file := path.Join("stdlib", entry.Name())
+		source, err := stdlibFS.ReadFile(file)
+		if err != nil {
+			panic(err)
+		}
+
+		p := parse.New(lexer.New(string(source)))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			panic(file + ": " + strings.Join(p.Errors(), "; "))
+		}
+
+		for _, name := range exportedNames(program) {
+			index[name] = file
+		}
+	}
+
+	return index
+}
@@ -0,0 +1,13 @@
+export fn identity(x) { x }
+
+export fn constant(x) { fn() { x } }
+
+export fn compose(f, g) { fn(x) { f(g(x)) } }
+
+export fn flip(f) { fn(a, b) { f(b, a) } }
+
+export fn curry(f) { fn(a) { fn(b) { f(a, b) } } }
+
+export fn uncurry(f) { fn(a, b) { f(a)(b) } }
+
+export fn times(n, f) { map(0..n, f) }
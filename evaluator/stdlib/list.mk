@@ -0,0 +1,23 @@
+export fn count(xs, pred) {
+  let n = 0;
+  for (x in xs) {
+    if (pred(x)) { n += 1; }
+  }
+  n
+}
+
+export fn sum(xs) { reduce(xs, fn(a, b) { a + b }, 0) }
+
+export fn product(xs) { reduce(xs, fn(a, b) { a * b }, 1) }
+
+export fn max(xs) {
+  reduce(rest(xs) ?? [], fn(a, b) { b > a ? b : a }, first(xs))
+}
+
+export fn min(xs) {
+  reduce(rest(xs) ?? [], fn(a, b) { b < a ? b : a }, first(xs))
+}
+
+export fn take(xs, n) { xs[:n] }
+
+export fn drop(xs, n) { xs[n:] }
@@ -0,0 +1 @@
+export fn capitalize(s) { s == "" ? s : s[0].upper() + s[1:] }
@@ -0,0 +1,364 @@
+package evaluator
+
+import (
+	"fmt"
+	"monkey/object"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+func init() {
+	for name, fn := range map[string]object.BuiltinFunction{
+		"split":       stringSplit,
+		"join":        stringJoin,
+		"trim":        stringTrim,
+		"trim_left":   stringTrimLeft,
+		"trim_right":  stringTrimRight,
+		"upper":       stringUpper,
+		"lower":       stringLower,
+		"replace":     stringReplace,
+		"contains":    stringContains,
+		"starts_with": stringStartsWith,
+		"ends_with":   stringEndsWith,
+		"repeat":      stringRepeat,
+		"pad_left":    stringPadLeft,
+		"pad_right":   stringPadRight,
+		"chars":       stringChars,
+		"format":      stringFormat,
+	} {
+		builtins[name] = &object.Builtin{Name: name, Fn: fn}
+	}
+}
+
+// stringArguments checks that args are want strings and returns their
+// values, for the builtin name.
+func stringArguments(name string, args []object.Object, want int) ([]string, *object.Error) {
+	if len(args) != want {
+		return nil, newError("wrong number of arguments. got=%d, want=%d", len(args), want)
+	}
+
+	values := make([]string, len(args))
+	for i, arg := range args {
+		str, ok := arg.(*object.String)
+		if !ok {
+			return nil, newError("argument to `%s` must be STRING, got %s", name, arg.Type())
+		}
+		values[i] = str.Value
+	}
+	return values, nil
+}
+
+func stringArray(values []string) *object.Array {
+	elements := make([]object.Object, len(values))
+	for i, value := range values {
+		elements[i] = &object.String{Value: value}
+	}
+	return &object.Array{Elements: elements}
+}
+
+// stringSplit splits around a separator, or around runs of white space when
+// none is given. An empty separator splits into characters.
+func stringSplit(args ...object.Object) object.Object {
+	if len(args) == 1 {
+		values, err := stringArguments("split", args, 1)
+		if err != nil {
+			return err
+		}
+		return stringArray(strings.Fields(values[0]))
+	}
+
+	values, err := stringArguments("split", args, 2)
+	if err != nil {
+		return err
+	}
+	return stringArray(strings.Split(values[0], values[1]))
+}
+
+func stringJoin(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	elements, err := arrayArgument("join", args[0])
+	if err != nil {
+		return err
+	}
+	sep, ok := args[1].(*object.String)
+	if !ok {
+		return newError("argument to `join` must be STRING, got %s", args[1].Type())
+	}
+
+	values := make([]string, len(elements))
+	for i, el := range elements {
+		str, ok := el.(*object.String)
+		if !ok {
+			return newError("argument to `join` must be ARRAY of STRING, got %s element", el.Type())
+		}
+		values[i] = str.Value
+	}
+	return &object.String{Value: strings.Join(values, sep.Value)}
+}
+
+// stringTrim removes leading and trailing white space, or the characters in
+// the optional cutset.
+func stringTrim(args ...object.Object) object.Object {
+	return trim("trim", args, strings.TrimFunc, strings.Trim)
+}
+
+func stringTrimLeft(args ...object.Object) object.Object {
+	return trim("trim_left", args, strings.TrimLeftFunc, strings.TrimLeft)
+}
+
+func stringTrimRight(args ...object.Object) object.Object {
+	return trim("trim_right", args, strings.TrimRightFunc, strings.TrimRight)
+}
+
+func trim(name string, args []object.Object,
+	trimSpace func(string, func(rune) bool) string, trimCutset func(string, string) string) object.Object {
+	if len(args) == 1 {
+		values, err := stringArguments(name, args, 1)
+		if err != nil {
+			return err
+		}
+		return &object.String{Value: trimSpace(values[0], unicode.IsSpace)}
+	}
+
+	values, err := stringArguments(name, args, 2)
+	if err != nil {
+		return err
+	}
+	return &object.String{Value: trimCutset(values[0], values[1])}
+}
+
+func stringUpper(args ...object.Object) object.Object {
+	values, err := stringArguments("upper", args, 1)
+	if err != nil {
+		return err
+	}
+	return &object.String{Value: strings.ToUpper(values[0])}
+}
+
+func stringLower(args ...object.Object) object.Object {
+	values, err := stringArguments("lower", args, 1)
+	if err != nil {
+		return err
+	}
+	return &object.String{Value: strings.ToLower(values[0])}
+}
+
+func stringReplace(args ...object.Object) object.Object {
+	values, err := stringArguments("replace", args, 3)
+	if err != nil {
+		return err
+	}
+	return &object.String{Value: strings.ReplaceAll(values[0], values[1], values[2])}
+}
+
+func stringContains(args ...object.Object) object.Object {
+	values, err := stringArguments("contains", args, 2)
+	if err != nil {
+		return err
+	}
+	return nativeBoolToBooleanObject(strings.Contains(values[0], values[1]))
+}
+
+func stringStartsWith(args ...object.Object) object.Object {
+	values, err := stringArguments("starts_with", args, 2)
+	if err != nil {
+		return err
+	}
+	return nativeBoolToBooleanObject(strings.HasPrefix(values[0], values[1]))
+}
+
+func stringEndsWith(args ...object.Object) object.Object {
+	values, err := stringArguments("ends_with", args, 2)
+	if err != nil {
+		return err
+	}
+	return nativeBoolToBooleanObject(strings.HasSuffix(values[0], values[1]))
+}
+
+// stringIndexOf returns the character index of the first occurrence of the
+// substring, or -1.
+func stringIndexOf(args ...object.Object) object.Object {
+	values, err := stringArguments("index_of", args, 2)
+	if err != nil {
+		return err
+	}
+
+	offset := strings.Index(values[0], values[1])
+	if offset < 0 {
+		return &object.Integer{Value: -1}
+	}
+	return &object.Integer{Value: int64(utf8.RuneCountInString(values[0][:offset]))}
+}
+
+func stringRepeat(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	str, ok := args[0].(*object.String)
+	if !ok {
+		return newError("argument to `repeat` must be STRING, got %s", args[0].Type())
+	}
+	count, ok := args[1].(*object.Integer)
+	if !ok {
+		return newError("argument to `repeat` must be INTEGER, got %s", args[1].Type())
+	}
+	if count.Value < 0 {
+		return newError("argument to `repeat` must not be negative, got %d", count.Value)
+	}
+	if len(str.Value) > 0 && count.Value > maxStringLength/int64(len(str.Value)) {
+		return newError("result of `repeat` is too long, max %d bytes", maxStringLength)
+	}
+
+	return &object.String{Value: strings.Repeat(str.Value, int(count.Value))}
+}
+
+// maxStringLength caps the strings repeat and pad build, so that a large
+// count is reported as an error instead of exhausting memory.
+const maxStringLength = 1 << 28
+
+func stringPadLeft(args ...object.Object) object.Object {
+	return pad("pad_left", args, func(s, padding string) string { return padding + s })
+}
+
+func stringPadRight(args ...object.Object) object.Object {
+	return pad("pad_right", args, func(s, padding string) string { return s + padding })
+}
+
+// pad extends a string to a width in characters with the optional pad
+// string, a space by default.
+func pad(name string, args []object.Object, join func(s, padding string) string) object.Object {
+	if len(args) != 2 && len(args) != 3 {
+		return newError("wrong number of arguments. got=%d, want=2 or 3", len(args))
+	}
+	str, ok := args[0].(*object.String)
+	if !ok {
+		return newError("argument to `%s` must be STRING, got %s", name, args[0].Type())
+	}
+	width, ok := args[1].(*object.Integer)
+	if !ok {
+		return newError("argument to `%s` must be INTEGER, got %s", name, args[1].Type())
+	}
+	padding := " "
+	if len(args) == 3 {
+		padStr, ok := args[2].(*object.String)
+		if !ok || padStr.Value == "" {
+			return newError("argument to `%s` must be a non-empty STRING, got %s", name, args[2].Inspect())
+		}
+		padding = padStr.Value
+	}
+
+	if width.Value > maxStringLength {
+		return newError("width of `%s` is too large, max %d", name, maxStringLength)
+	}
+
+	missing := int(width.Value) - utf8.RuneCountInString(str.Value)
+	if missing <= 0 {
+		return str
+	}
+
+	padRunes := []rune(padding)
+	runes := make([]rune, missing)
+	for i := range runes {
+		runes[i] = padRunes[i%len(padRunes)]
+	}
+	return &object.String{Value: join(str.Value, string(runes))}
+}
+
+func stringChars(args ...object.Object) object.Object {
+	values, err := stringArguments("chars", args, 1)
+	if err != nil {
+		return err
+	}
+	return stringArray(strings.Split(values[0], ""))
+}
+
+// stringFormat formats its arguments with Go's fmt verbs. Integers, floats,
+// strings and booleans are passed as Go values, anything else as its
+// Inspect text. Each verb must suit its argument and there must be exactly
+// one argument per verb, so fmt's %!d(...) markers never reach the result.
+func stringFormat(args ...object.Object) object.Object {
+	if len(args) < 1 {
+		return newError("wrong number of arguments. got=%d, want at least 1", len(args))
+	}
+	format, ok := args[0].(*object.String)
+	if !ok {
+		return newError("argument to `format` must be STRING, got %s", args[0].Type())
+	}
+
+	verbs, err := formatVerbs(format.Value)
+	if err != nil {
+		return err
+	}
+	if len(verbs) != len(args)-1 {
+		return newError("wrong number of arguments to `format`. got=%d, want=%d", len(args)-1, len(verbs))
+	}
+
+	values := make([]interface{}, len(args)-1)
+	for i, arg := range args[1:] {
+		if !strings.ContainsRune(formatVerbsFor(arg), verbs[i]) {
+			return newError("verb %%%c in `format` does not suit %s", verbs[i], arg.Type())
+		}
+
+		switch arg := arg.(type) {
+		case *object.Integer:
+			values[i] = arg.Value
+		case *object.Float:
+			values[i] = arg.Value
+		case *object.String:
+			values[i] = arg.Value
+		case *object.Boolean:
+			values[i] = arg.Value
+		default:
+			values[i] = arg.Inspect()
+		}
+	}
+
+	return &object.String{Value: fmt.Sprintf(format.Value, values...)}
+}
+
+// formatVerbs returns the verbs of a format string in order, skipping %%.
+// Flags, width and precision are allowed, but not * or explicit argument
+// indexes, which would break the one-argument-per-verb rule.
+func formatVerbs(format string) ([]rune, *object.Error) {
+	var verbs []rune
+	runes := []rune(format)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '%' {
+			continue
+		}
+		i++
+		for i < len(runes) && strings.ContainsRune("+-# 0123456789.", runes[i]) {
+			i++
+		}
+		if i == len(runes) {
+			return nil, newError("format %q ends in an incomplete verb", format)
+		}
+		switch verb := runes[i]; verb {
+		case '%':
+		case '*', '[':
+			return nil, newError("format %q uses %c, which `format` does not support", format, verb)
+		default:
+			verbs = append(verbs, verb)
+		}
+	}
+	return verbs, nil
+}
+
+// formatVerbsFor returns the verbs that suit the argument's type.
+func formatVerbsFor(arg object.Object) string {
+	switch arg.(type) {
+	case *object.Integer:
+		return "vdbox"
+	case *object.Float:
+		return "vfFeEgG"
+	case *object.String:
+		return "vsqxX"
+	case *object.Boolean:
+		return "vt"
+	default:
+		return "vs"
+	}
+}
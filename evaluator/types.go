@@ -0,0 +1,151 @@
+package evaluator
+
+import (
+	"math"
+	"monkey/object"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	for name, fn := range map[string]object.BuiltinFunction{
+		"type":  typeOf,
+		"str":   convertStr,
+		"repr":  reprOf,
+		"int":   convertInt,
+		"float": convertFloat,
+		"bool":  convertBool,
+	} {
+		builtins[name] = &object.Builtin{Name: name, Fn: fn}
+	}
+
+	for name, types := range typePredicates {
+		builtins[name] = &object.Builtin{Name: name, Fn: isType(types...)}
+	}
+}
+
+// typePredicates maps each is_* builtin to the types it accepts.
+var typePredicates = map[string][]object.ObjectType{
+	"is_int":      {object.INTEGER_OBJ},
+	"is_float":    {object.FLOAT_OBJ},
+	"is_number":   {object.INTEGER_OBJ, object.FLOAT_OBJ},
+	"is_string":   {object.STRING_OBJ},
+	"is_bool":     {object.BOOLEAN_OBJ},
+	"is_null":     {object.NULL_OBJ},
+	"is_array":    {object.ARRAY_OBJ},
+	"is_tuple":    {object.TUPLE_OBJ},
+	"is_hash":     {object.HASH_OBJ},
+	"is_range":    {object.RANGE_OBJ},
+	"is_result":   {object.RESULT_OBJ},
+	"is_function": {object.FUNCTION_OBJ, object.Bulitin_OBJ},
+}
+
+func isType(types ...object.ObjectType) object.BuiltinFunction {
+	return func(args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments. got=%d, want=1", len(args))
+		}
+		for _, t := range types {
+			if args[0].Type() == t {
+				return TRUE
+			}
+		}
+		return FALSE
+	}
+}
+
+// typeOf returns the type name of its argument, e.g. "INTEGER".
+func typeOf(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	return &object.String{Value: string(args[0].Type())}
+}
+
+// convertStr converts its argument to a string: strings are returned as
+// they are, anything else as it is printed.
+func convertStr(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	if str, ok := args[0].(*object.String); ok {
+		return str
+	}
+	return &object.String{Value: args[0].Inspect()}
+}
+
+// reprOf returns its argument as Monkey source, with strings quoted even at
+// the top level: repr("a") is "\"a\"" where str("a") is "a".
+func reprOf(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	return &object.String{Value: object.Repr(args[0])}
+}
+
+// convertInt converts a float (truncating towards zero), a boolean or a
+// decimal string to an integer.
+func convertInt(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		return arg
+	case *object.Float:
+		if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) ||
+			arg.Value >= math.MaxInt64 || arg.Value < math.MinInt64 {
+			return newError("cannot convert %s to INTEGER", arg.Inspect())
+		}
+		return &object.Integer{Value: int64(arg.Value)}
+	case *object.Boolean:
+		if arg.Value {
+			return &object.Integer{Value: 1}
+		}
+		return &object.Integer{Value: 0}
+	case *object.String:
+		value, err := strconv.ParseInt(strings.TrimSpace(arg.Value), 10, 64)
+		if err != nil {
+			return newError("cannot convert %q to INTEGER", arg.Value)
+		}
+		return &object.Integer{Value: value}
+	default:
+		return newError("argument to `int` not supported, got %s", args[0].Type())
+	}
+}
+
+func convertFloat(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Float:
+		return arg
+	case *object.Integer:
+		return &object.Float{Value: float64(arg.Value)}
+	case *object.Boolean:
+		if arg.Value {
+			return &object.Float{Value: 1}
+		}
+		return &object.Float{Value: 0}
+	case *object.String:
+		value, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+		if err != nil {
+			return newError("cannot convert %q to FLOAT", arg.Value)
+		}
+		return &object.Float{Value: value}
+	default:
+		return newError("argument to `float` not supported, got %s", args[0].Type())
+	}
+}
+
+// convertBool reports whether its argument is truthy, the way if and while
+// decide: only false and null are false.
+func convertBool(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	return nativeBoolToBooleanObject(isTruthy(args[0]))
+}
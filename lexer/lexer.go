@@ -2,13 +2,16 @@ package lexer
 
 import (
 	"monkey/token"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 type Lexer struct {
 	input        string
-	position     int  // current position in input (points to current char)
-	readPosition int  // current reading position in input (after current char)
-	ch           byte // current char under examination
+	position     int  // current byte offset in input (points to current char)
+	readPosition int  // current reading byte offset in input (after current char)
+	ch           rune // current char under examination
 }
 
 func New(input string) *Lexer {
@@ -17,14 +20,24 @@ func New(input string) *Lexer {
 	return l
 }
 
+// PeekToken returns the token NextToken would return, without consuming it.
+func (l *Lexer) PeekToken() token.Token {
+	saved := *l
+	tok := l.NextToken()
+	*l = saved
+	return tok
+}
+
 func (l *Lexer) readChar() {
+	l.position = l.readPosition
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
+		l.readPosition++
 	} else {
-		l.ch = l.input[l.readPosition]
+		r, size := utf8.DecodeRuneInString(l.input[l.readPosition:])
+		l.ch = r
+		l.readPosition += size
 	}
-	l.position = l.readPosition
-	l.readPosition++
 }
 
 func (l *Lexer) NextToken() token.Token {
@@ -39,11 +52,17 @@ func (l *Lexer) NextToken() token.Token {
 			l.readChar()
 			literal := string(ch) + string(l.ch)
 			tok = token.Token{Type: token.EQ, Literal: literal}
+		} else if l.peekChar() == '>' {
+			tok = l.readTwoCharToken(token.ARROW)
 		} else {
 			tok = newToken(token.ASSIGN, l.ch)
 		}
 	case '+':
-		tok = newToken(token.PLUS, '+')
+		if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.PLUS_ASSIGN)
+		} else {
+			tok = newToken(token.PLUS, '+')
+		}
 	case '(':
 		tok = newToken(token.LPAREN, '(')
 	case ')':
@@ -57,7 +76,11 @@ func (l *Lexer) NextToken() token.Token {
 	case ';':
 		tok = newToken(token.SEMICOLON, ';')
 	case '-':
-		tok = newToken(token.MINUS, '-')
+		if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.MINUS_ASSIGN)
+		} else {
+			tok = newToken(token.MINUS, '-')
+		}
 	case '!':
 		if l.peekChar() == '=' {
 			ch := l.ch
@@ -68,13 +91,45 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.BANG, '!')
 		}
 	case '/':
-		tok = newToken(token.SLASH, '/')
+		if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.SLASH_ASSIGN)
+		} else {
+			tok = newToken(token.SLASH, '/')
+		}
 	case '*':
-		tok = newToken(token.ASTERISK, '*')
+		if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.ASTERISK_ASSIGN)
+		} else {
+			tok = newToken(token.ASTERISK, '*')
+		}
 	case '<':
-		tok = newToken(token.LT, '<')
+		if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.LT_EQ)
+		} else {
+			tok = newToken(token.LT, '<')
+		}
 	case '>':
-		tok = newToken(token.GT, '>')
+		if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.GT_EQ)
+		} else {
+			tok = newToken(token.GT, '>')
+		}
+	case '|':
+		if l.peekChar() == '>' {
+			tok = l.readTwoCharToken(token.PIPE)
+		} else {
+			tok = newToken(token.ILLEGAL, l.ch)
+		}
+	case '?':
+		if l.peekChar() == '?' {
+			tok = l.readTwoCharToken(token.NULLISH)
+		} else if l.peekChar() == '.' {
+			tok = l.readTwoCharToken(token.OPTIONAL_CHAIN)
+		} else if l.followsOperand() {
+			tok = newToken(token.PROPAGATE, '?')
+		} else {
+			tok = newToken(token.QUESTION, '?')
+		}
 	case '"':
 		tok.Type = token.STRING
 		tok.Literal = l.readString()
@@ -84,6 +139,20 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.RBRACKET, ']')
 	case ':':
 		tok = newToken(token.COLON, ':')
+	case '.':
+		if l.peekChar() == '.' && l.peekCharAt(1) == '.' {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+		} else if l.peekChar() == '.' && l.peekCharAt(1) == '=' {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.RANGE_INCLUSIVE, Literal: "..="}
+		} else if l.peekChar() == '.' {
+			tok = l.readTwoCharToken(token.RANGE)
+		} else {
+			tok = newToken(token.DOT, '.')
+		}
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
@@ -93,8 +162,7 @@ func (l *Lexer) NextToken() token.Token {
 			tok.Type = token.LookupIdent(tok.Literal)
 			return tok
 		} else if isDigit(l.ch) {
-			tok.Literal = l.readNumber()
-			tok.Type = token.INT
+			tok.Literal, tok.Type = l.readNumber()
 			return tok
 		}
 		tok = newToken(token.ILLEGAL, l.ch)
@@ -104,20 +172,58 @@ func (l *Lexer) NextToken() token.Token {
 	return tok
 }
 
+// followsOperand reports whether the current char directly follows the end
+// of an operand, with no white space in between. A ? written that way is the
+// postfix propagation operator; a ? after white space starts a ternary.
+func (l *Lexer) followsOperand() bool {
+	if l.position == 0 {
+		return false
+	}
+	prev, _ := utf8.DecodeLastRuneInString(l.input[:l.position])
+	return isLetter(prev) || isDigit(prev) || strings.ContainsRune(")]}\"", prev)
+}
+
+// readTwoCharToken consumes the current and the next char as a single token.
+func (l *Lexer) readTwoCharToken(tokenType token.TokenType) token.Token {
+	ch := l.ch
+	l.readChar()
+	return token.Token{Type: tokenType, Literal: string(ch) + string(l.ch)}
+}
+
+// readString reads a string literal, resolving the escapes \", \\, \n, \t
+// and \r. A backslash before any other character is kept as it is.
 func (l *Lexer) readString() string {
-	position := l.position + 1
+	var out strings.Builder
 	for {
 		l.readChar()
-		if l.ch == '"' || l.ch == 0 {
-			break
+		switch l.ch {
+		case '"', 0:
+			return out.String()
+		case '\\':
+			if escaped, ok := escapes[l.peekChar()]; ok {
+				l.readChar()
+				out.WriteRune(escaped)
+				continue
+			}
 		}
+		out.WriteRune(l.ch)
 	}
-	return l.input[position:l.position]
 }
 
+// escapes maps the character after a backslash to the character it stands
+// for in a string literal.
+var escapes = map[rune]rune{
+	'"':  '"',
+	'\\': '\\',
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+}
+
+// readIdintifier reads a letter followed by letters and digits, as in Go.
 func (l *Lexer) readIdintifier() string {
 	position := l.position
-	for isLetter(l.ch) {
+	for isLetter(l.ch) || unicode.IsDigit(l.ch) {
 		l.readChar()
 	}
 	return l.input[position: l.position]
@@ -129,33 +235,78 @@ func (l *Lexer) skipWhiteSpace() {
 	}
 }
 
-func (l *Lexer) readNumber() string {
+// readNumber reads an integer, or a float when the digits are followed by a
+// '.' and another digit, so that 1..5 stays a range, or by an exponent such
+// as e21 or e-7.
+func (l *Lexer) readNumber() (string, token.TokenType) {
 	position := l.position
+	var tokenType token.TokenType = token.INT
 	for isDigit(l.ch) {
 		l.readChar()
 	}
-	return l.input[position: l.position]
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
+		l.readChar()
+		for isDigit(l.ch) {
+			l.readChar()
+		}
+	}
+	if l.readExponent() {
+		tokenType = token.FLOAT
+	}
+	return l.input[position: l.position], tokenType
 }
 
-func (l *Lexer) peekChar() byte {
-	if l.readPosition >= len(l.input) {
+// readExponent consumes an exponent part such as e5, E+21 or e-7, and
+// leaves the input alone when the current char does not start one.
+func (l *Lexer) readExponent() bool {
+	if l.ch != 'e' && l.ch != 'E' {
+		return false
+	}
+	next := l.peekChar()
+	if next == '+' || next == '-' {
+		next = l.peekCharAt(1)
+	}
+	if !isDigit(next) {
+		return false
+	}
+
+	l.readChar()
+	if l.ch == '+' || l.ch == '-' {
+		l.readChar()
+	}
+	for isDigit(l.ch) {
+		l.readChar()
+	}
+	return true
+}
+
+func (l *Lexer) peekChar() rune {
+	return l.peekCharAt(0)
+}
+
+// peekCharAt looks offset chars past the next one without consuming input.
+func (l *Lexer) peekCharAt(offset int) rune {
+	position := l.readPosition
+	for ; offset > 0 && position < len(l.input); offset-- {
+		_, size := utf8.DecodeRuneInString(l.input[position:])
+		position += size
+	}
+	if position >= len(l.input) {
 		return 0
-	} else {
-		return l.input[l.readPosition]
 	}
+	r, _ := utf8.DecodeRuneInString(l.input[position:])
+	return r
 }
 
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
 
-func isLetter(ch byte) bool {
-	if (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch == '_') {
-		return true
-	}
-	return false
+func isLetter(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_'
 }
 
-func isDigit(ch byte) bool {
+func isDigit(ch rune) bool {
 	return ch >= '0' && ch <= '9'
 }
\ No newline at end of file
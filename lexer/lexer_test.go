@@ -26,6 +26,20 @@ func TestNextToken(t *testing.T) {
 				"foo bar"
 				[1,2]
 				{"foo": "bar"}
+				x += 1; x -= 1; x *= 2; x /= 2;
+				while for break continue in
+				match _ =>
+				[a, ...rest]
+				a ? b : c ?? d?.e r? - f()?
+				xs |> sum |
+				0..10 1..=n
+				k not in h
+				a <= b >= c
+				throw try catch finally
+				import export
+				1.5 2..3 4.x
+				1e+21 2.5E-7 3e x.e1
+				"say \"hi\"\n\t\\ \d"
 				`
 
 	tests := []struct {
@@ -117,6 +131,92 @@ func TestNextToken(t *testing.T) {
 		{token.COLON, ":"},
 		{token.STRING, "bar"},
 		{token.RBRACE, "}"},
+		{token.IDENT, "x"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.MINUS_ASSIGN, "-="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.SLASH_ASSIGN, "/="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.WHILE, "while"},
+		{token.FOR, "for"},
+		{token.BREAK, "break"},
+		{token.CONTINUE, "continue"},
+		{token.IN, "in"},
+		{token.MATCH, "match"},
+		{token.IDENT, "_"},
+		{token.ARROW, "=>"},
+		{token.LBRACKET, "["},
+		{token.IDENT, "a"},
+		{token.COMMA, ","},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "rest"},
+		{token.RBRACKET, "]"},
+		{token.IDENT, "a"},
+		{token.QUESTION, "?"},
+		{token.IDENT, "b"},
+		{token.COLON, ":"},
+		{token.IDENT, "c"},
+		{token.NULLISH, "??"},
+		{token.IDENT, "d"},
+		{token.OPTIONAL_CHAIN, "?."},
+		{token.IDENT, "e"},
+		{token.IDENT, "r"},
+		{token.PROPAGATE, "?"},
+		{token.MINUS, "-"},
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.RPAREN, ")"},
+		{token.PROPAGATE, "?"},
+		{token.IDENT, "xs"},
+		{token.PIPE, "|>"},
+		{token.IDENT, "sum"},
+		{token.ILLEGAL, "|"},
+		{token.INT, "0"},
+		{token.RANGE, ".."},
+		{token.INT, "10"},
+		{token.INT, "1"},
+		{token.RANGE_INCLUSIVE, "..="},
+		{token.IDENT, "n"},
+		{token.IDENT, "k"},
+		{token.IDENT, "not"},
+		{token.IN, "in"},
+		{token.IDENT, "h"},
+		{token.IDENT, "a"},
+		{token.LT_EQ, "<="},
+		{token.IDENT, "b"},
+		{token.GT_EQ, ">="},
+		{token.IDENT, "c"},
+		{token.THROW, "throw"},
+		{token.TRY, "try"},
+		{token.CATCH, "catch"},
+		{token.FINALLY, "finally"},
+		{token.IMPORT, "import"},
+		{token.EXPORT, "export"},
+		{token.FLOAT, "1.5"},
+		{token.INT, "2"},
+		{token.RANGE, ".."},
+		{token.INT, "3"},
+		{token.INT, "4"},
+		{token.DOT, "."},
+		{token.IDENT, "x"},
+		{token.FLOAT, "1e+21"},
+		{token.FLOAT, "2.5E-7"},
+		{token.INT, "3"},
+		{token.IDENT, "e"},
+		{token.IDENT, "x"},
+		{token.DOT, "."},
+		{token.IDENT, "e1"},
+		{token.STRING, "say \"hi\"\n\t\\ \\d"},
 		{token.EOF, ""},
 	}
 
@@ -132,4 +232,60 @@ func TestNextToken(t *testing.T) {
 			t.Fatalf("test[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
 		}
 	}
-}
\ No newline at end of file
+}
+func TestPeekToken(t *testing.T) {
+	l := New("a ?? b")
+
+	if tok := l.NextToken(); tok.Type != token.IDENT {
+		t.Fatalf("first token wrong. expected=%q, got=%q", token.IDENT, tok.Type)
+	}
+
+	for i := 0; i < 2; i++ {
+		if tok := l.PeekToken(); tok.Type != token.NULLISH {
+			t.Fatalf("peek %d - tokentype wrong. expected=%q, got=%q", i, token.NULLISH, tok.Type)
+		}
+	}
+
+	if tok := l.NextToken(); tok.Type != token.NULLISH {
+		t.Fatalf("token after peek wrong. expected=%q, got=%q", token.NULLISH, tok.Type)
+	}
+	if tok := l.NextToken(); tok.Literal != "b" {
+		t.Fatalf("last token wrong. expected=%q, got=%q", "b", tok.Literal)
+	}
+}
+
+func TestUnicodeInput(t *testing.T) {
+	input := `let café = "héllo, 世界"; naïve_2 + π; x1 § y`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "café"},
+		{token.ASSIGN, "="},
+		{token.STRING, "héllo, 世界"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "naïve_2"},
+		{token.PLUS, "+"},
+		{token.IDENT, "π"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x1"},
+		{token.ILLEGAL, "§"},
+		{token.IDENT, "y"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("test[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("test[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
@@ -2,12 +2,19 @@ package main
 
 import (
 	"fmt"
+	"monkey/evaluator"
 	"monkey/repl"
 	"os"
 	"os/user"
+	"path/filepath"
 )
 
 func main() {
+	evaluator.SearchPath = filepath.SplitList(os.Getenv("MONKEY_PATH"))
+
+	if len(os.Args) > 1 {
+		os.Exit(repl.RunFile(os.Args[1], os.Stderr))
+	}
 
 	user, err := user.Current()
 	if err != nil {
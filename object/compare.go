@@ -0,0 +1,185 @@
+package object
+
+import "strings"
+
+// Equals reports whether a and b are structurally equal. An integer equals
+// a float of the same value. Arrays, tuples and hashes are compared element
+// by element; functions and builtins compare by identity. Containers that
+// contain themselves compare equal where the same pair recurs.
+func Equals(a, b Object) bool {
+	c := &comparer{visiting: map[[2]Object]bool{}}
+	return c.equals(a, b)
+}
+
+// Compare orders a and b, returning -1, 0 or +1. Integers and floats compare
+// numerically, strings lexicographically and arrays and tuples element-wise,
+// a shorter one ordering first when it is a prefix of the longer one. ok is false
+// when the two values have no defined order.
+func Compare(a, b Object) (result int, ok bool) {
+	c := &comparer{visiting: map[[2]Object]bool{}}
+	return c.compare(a, b)
+}
+
+// comparer walks two values side by side. visiting holds the pairs of
+// containers being compared further up, so that cyclic values terminate.
+type comparer struct {
+	visiting map[[2]Object]bool
+}
+
+func (c *comparer) equals(a, b Object) bool {
+	if x, y, ok := mixedNumbers(a, b); ok {
+		return x == y
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a := a.(type) {
+	case *Integer:
+		return a.Value == b.(*Integer).Value
+	case *Float:
+		return a.Value == b.(*Float).Value
+	case *String:
+		return a.Value == b.(*String).Value
+	case *Boolean:
+		return a.Value == b.(*Boolean).Value
+	case *Null:
+		return true
+	case *Array:
+		return c.container(a, b, func() bool {
+			return c.elementsEqual(a.Elements, b.(*Array).Elements)
+		})
+	case *Tuple:
+		return c.container(a, b, func() bool {
+			return c.elementsEqual(a.Elements, b.(*Tuple).Elements)
+		})
+	case *Hash:
+		other := b.(*Hash)
+		if a.Len() != other.Len() {
+			return false
+		}
+		return c.container(a, b, func() bool {
+			for _, pair := range a.Entries() {
+				otherPair, ok := other.Get(pair.Key)
+				if !ok || !c.equals(pair.Value, otherPair.Value) {
+					return false
+				}
+			}
+			return true
+		})
+	case *Result:
+		other := b.(*Result)
+		return a.Ok == other.Ok && c.container(a, b, func() bool {
+			return c.equals(a.Value, other.Value)
+		})
+	case *Range:
+		other := b.(*Range)
+		length := a.Len()
+		if length != other.Len() {
+			return false
+		}
+		return length == 0 || a.At(0) == other.At(0) && (length == 1 || a.Step == other.Step)
+	default:
+		return a == b
+	}
+}
+
+// container compares the containers a and b with elements, unless they are
+// the same value or already being compared further up, in which case they
+// are taken to be equal.
+func (c *comparer) container(a, b Object, elements func() bool) bool {
+	pair := [2]Object{a, b}
+	if a == b || c.visiting[pair] {
+		return true
+	}
+	c.visiting[pair] = true
+	defer delete(c.visiting, pair)
+	return elements()
+}
+
+func (c *comparer) compare(a, b Object) (int, bool) {
+	if x, y, ok := mixedNumbers(a, b); ok {
+		return c.compare(&Float{Value: x}, &Float{Value: y})
+	}
+	if a.Type() != b.Type() {
+		return 0, false
+	}
+
+	switch a := a.(type) {
+	case *Integer:
+		other := b.(*Integer)
+		switch {
+		case a.Value < other.Value:
+			return -1, true
+		case a.Value > other.Value:
+			return 1, true
+		}
+		return 0, true
+	case *Float:
+		other := b.(*Float)
+		switch {
+		case a.Value < other.Value:
+			return -1, true
+		case a.Value > other.Value:
+			return 1, true
+		case a.Value == other.Value:
+			return 0, true
+		}
+		return 0, false // NaN
+	case *String:
+		return strings.Compare(a.Value, b.(*String).Value), true
+	case *Array:
+		return c.compareContainers(a, b, a.Elements, b.(*Array).Elements)
+	case *Tuple:
+		return c.compareContainers(a, b, a.Elements, b.(*Tuple).Elements)
+	}
+
+	return 0, false
+}
+
+// mixedNumbers returns the values of a and b as floats when one is an
+// integer and the other a float, the way arithmetic on them converts.
+func mixedNumbers(a, b Object) (x, y float64, ok bool) {
+	switch a := a.(type) {
+	case *Integer:
+		if b, isFloat := b.(*Float); isFloat {
+			return float64(a.Value), b.Value, true
+		}
+	case *Float:
+		if b, isInt := b.(*Integer); isInt {
+			return a.Value, float64(b.Value), true
+		}
+	}
+	return 0, 0, false
+}
+
+func (c *comparer) elementsEqual(a, b []Object) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, el := range a {
+		if !c.equals(el, b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareContainers orders the elements of the arrays or tuples a and b.
+// Like container, it takes a pair already being compared to be equal.
+func (c *comparer) compareContainers(a, b Object, as, bs []Object) (int, bool) {
+	pair := [2]Object{a, b}
+	if a == b || c.visiting[pair] {
+		return 0, true
+	}
+	c.visiting[pair] = true
+	defer delete(c.visiting, pair)
+
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		result, ok := c.compare(as[i], bs[i])
+		if !ok || result != 0 {
+			return result, ok
+		}
+	}
+	return c.compare(&Integer{Value: int64(len(as))}, &Integer{Value: int64(len(bs))})
+}
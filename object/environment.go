@@ -6,8 +6,10 @@ func NewEnvironment() *Environment {
 }
 
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store    map[string]Object
+	outer    *Environment
+	file     string // the source file evaluated in this environment, if any
+	function bool   // whether this is the environment of a function call
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
@@ -23,8 +25,63 @@ func (e *Environment) Set(name string, val Object) Object {
 	return val
 }
 
+// Assign rebinds an existing name in the nearest scope that defines it.
+// It reports false if the name is not bound anywhere.
+func (e *Environment) Assign(name string, val Object) (Object, bool) {
+	if _, ok := e.store[name]; ok {
+		e.store[name] = val
+		return val, true
+	}
+	if e.outer != nil {
+		return e.outer.Assign(name, val)
+	}
+	return nil, false
+}
+
 func NewEnclosedEnvironment(outer *Environment) *Environment {
 	env := NewEnvironment()
 	env.outer = outer
 	return env
-}
\ No newline at end of file
+}
+// Copy returns an environment enclosed by the same outer one, holding its
+// own copy of the bindings of e.
+func (e *Environment) Copy() *Environment {
+	env := NewEnclosedEnvironment(e.outer)
+	for name, val := range e.store {
+		env.store[name] = val
+	}
+	env.file, env.function = e.file, e.function
+	return env
+}
+
+// NewFunctionEnvironment returns the environment of a call to a function
+// defined in outer.
+func NewFunctionEnvironment(outer *Environment) *Environment {
+	env := NewEnclosedEnvironment(outer)
+	env.function = true
+	return env
+}
+
+// InFunction reports whether code in this environment runs inside a
+// function call, rather than at the top level of a program or module.
+func (e *Environment) InFunction() bool {
+	if !e.function && e.outer != nil {
+		return e.outer.InFunction()
+	}
+	return e.function
+}
+
+// SetFile records the source file whose code runs in this environment, so
+// that imports in it resolve relative to that file.
+func (e *Environment) SetFile(path string) {
+	e.file = path
+}
+
+// File returns the source file of the nearest enclosing environment that
+// has one, or "" for code that does not come from a file.
+func (e *Environment) File() string {
+	if e.file == "" && e.outer != nil {
+		return e.outer.File()
+	}
+	return e.file
+}
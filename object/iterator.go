@@ -0,0 +1,85 @@
+package object
+
+import "unicode/utf8"
+
+// Iterator yields the elements of a collection one at a time. Next returns
+// the key of the element (its index for sequences), the element itself and
+// false once the iterator is exhausted.
+type Iterator interface {
+	Next() (Object, Object, bool)
+}
+
+// Iterable is implemented by every object a for-in loop can walk over.
+type Iterable interface {
+	Iterator() Iterator
+}
+
+type arrayIterator struct {
+	array *Array
+	index int
+}
+
+func (it *arrayIterator) Next() (Object, Object, bool) {
+	if it.index >= len(it.array.Elements) {
+		return nil, nil, false
+	}
+	key := &Integer{Value: int64(it.index)}
+	value := it.array.Elements[it.index]
+	it.index++
+	return key, value, true
+}
+
+func (a *Array) Iterator() Iterator { return &arrayIterator{array: a} }
+
+type stringIterator struct {
+	value   string
+	offset  int // byte offset of the next rune
+	runeIdx int64
+}
+
+func (it *stringIterator) Next() (Object, Object, bool) {
+	if it.offset >= len(it.value) {
+		return nil, nil, false
+	}
+	r, size := utf8.DecodeRuneInString(it.value[it.offset:])
+	key := &Integer{Value: it.runeIdx}
+	it.offset += size
+	it.runeIdx++
+	return key, &String{Value: string(r)}, true
+}
+
+func (s *String) Iterator() Iterator { return &stringIterator{value: s.Value} }
+
+type hashIterator struct {
+	pairs []HashPair
+	index int
+}
+
+func (it *hashIterator) Next() (Object, Object, bool) {
+	if it.index >= len(it.pairs) {
+		return nil, nil, false
+	}
+	pair := it.pairs[it.index]
+	it.index++
+	return pair.Key, pair.Value, true
+}
+
+// Iterator walks the pairs in insertion order.
+func (h *Hash) Iterator() Iterator { return &hashIterator{pairs: h.Entries()} }
+
+type rangeIterator struct {
+	rng   *Range
+	index int64
+}
+
+func (it *rangeIterator) Next() (Object, Object, bool) {
+	if it.index >= it.rng.Len() {
+		return nil, nil, false
+	}
+	key := &Integer{Value: it.index}
+	value := &Integer{Value: it.rng.At(it.index)}
+	it.index++
+	return key, value, true
+}
+
+func (r *Range) Iterator() Iterator { return &rangeIterator{rng: r} }
@@ -1,14 +1,17 @@
 package object
 
 import (
-	"bytes"
 	"fmt"
 	"hash/fnv"
+	"math"
 	"monkey/ast"
+	"strconv"
+	"strings"
 )
 
 const (
 	INTEGER_OBJ      = "INTEGER"
+	FLOAT_OBJ        = "FLOAT"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	NULL_OBJ         = "NULL"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
@@ -18,6 +21,12 @@ const (
 	Bulitin_OBJ 	 = "BUILTIN"
 	ARRAY_OBJ		 = "ARRAY"
 	HASH_OBJ		 = "HASH"
+	BREAK_OBJ        = "BREAK"
+	CONTINUE_OBJ     = "CONTINUE"
+	RANGE_OBJ        = "RANGE"
+	RESULT_OBJ       = "RESULT"
+	MODULE_OBJ       = "MODULE"
+	TUPLE_OBJ        = "TUPLE"
 )
 
 type ObjectType string
@@ -47,6 +56,43 @@ func (i *Integer) HashKey() HashKey {
 	return HashKey{Field: i.Type(), Value: uint64(i.Value)}
 }
 
+// Float is a 64-bit floating point number. A float with a whole value that
+// every integer near it can be told apart from hashes like the equal
+// integer, so 1.0 and 1 are the same hash key. Other floats are not
+// hashable, since keys that are equal only up to rounding would be
+// surprising.
+type Float struct {
+	Value float64
+}
+
+// maxExactFloat bounds the whole floats that are hashable: below it every
+// integer converts to a distinct float.
+const maxExactFloat = 1 << 53
+
+// Inspect prints the shortest representation that reads back as the same
+// value, with a decimal point or an exponent so that it is not taken for an
+// integer. Infinities and NaN print as +Inf, -Inf and NaN, which have no
+// literal syntax and do not read back.
+func (f *Float) Inspect() string {
+	out := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(out, ".eIN") {
+		out += ".0"
+	}
+	return out
+}
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
+// hashable reports whether f has a whole value below maxExactFloat.
+func (f *Float) hashable() bool {
+	return f.Value == math.Trunc(f.Value) && math.Abs(f.Value) < maxExactFloat
+}
+
+// HashKey returns the hash key of the equal integer. Only call it on floats
+// for which IsHashable holds.
+func (f *Float) HashKey() HashKey {
+	return (&Integer{Value: int64(f.Value)}).HashKey()
+}
+
 type Boolean struct {
 	Value bool
 }
@@ -75,35 +121,67 @@ type ReturnValue struct {
 func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
 func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
 
+// Break and Continue signal loop control flow, propagating through
+// blocks the same way ReturnValue does until a loop consumes them.
+type Break struct{}
+
+func (b *Break) Inspect() string  { return "break" }
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+
+type Continue struct{}
+
+func (c *Continue) Inspect() string  { return "continue" }
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+
+// Result is the value of ok(v) or err(e), an error returned as a value
+// instead of thrown.
+type Result struct {
+	Ok    bool
+	Value Object // the ok value or the err payload
+}
+
+func (r *Result) Type() ObjectType { return RESULT_OBJ }
+func (r *Result) Inspect() string  { return Repr(r) }
+
+// Module is the value of `import "path" as name`. Its exports are read
+// with name.export.
+type Module struct {
+	Path    string // the resolved file path
+	Exports map[string]Object
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) Inspect() string  { return "module(" + m.Path + ")" }
+
 type Error struct {
 	Message string
+	Kind    string   // set by throw; errors raised by the interpreter leave it empty
+	Value   Object   // the thrown value, nil for errors raised by the interpreter
+	Stack   []string // names of the functions the error propagated out of, innermost first
 }
 
 func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
 
 type Function struct {
-	Parameters []*ast.Identifier
+	Parameters []ast.Expression
 	Body       *ast.BlockStatement
 	Env        *Environment
+	Name       string // empty for anonymous functions
 }
 
+// Inspect prints the signature of the function, e.g. fn add(a, b).
 func (f *Function) Inspect() string {
-	var out bytes.Buffer
-
 	params := []string{}
 	for _, p := range f.Parameters {
 		params = append(params, p.String())
 	}
 
-	out.WriteString("fn")
-	out.WriteString("(")
-	out.WriteString(fmt.Sprintf("%s", params))
-	out.WriteString(") {\n")
-	out.WriteString(f.Body.String())
-	out.WriteString("\n}")
-
-	return out.String()
+	name := "fn"
+	if f.Name != "" {
+		name += " " + f.Name
+	}
+	return name + "(" + strings.Join(params, ", ") + ")"
 }
 
 func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
@@ -115,16 +193,28 @@ type String struct {
 func (s *String) Inspect() string  { return s.Value }
 func (s *String) Type() ObjectType { return STRING_OBJ }
 func (s *String) HashKey() HashKey {
+	return HashKey{Field: s.Type(), Value: hashString(s.Value)}
+}
+
+// hashString hashes the value of a String key. It is a variable so that
+// tests can force collisions.
+var hashString = func(value string) uint64 {
 	h := fnv.New64a()
-	h.Write([]byte(s.Value))
-	return HashKey{Field: s.Type(), Value: h.Sum64()}
+	h.Write([]byte(value))
+	return h.Sum64()
 }
 
 type Builtin struct {
-	Fn BuiltinFunction
+	Name string // the name it is bound to, for printing
+	Fn   BuiltinFunction
 }
 
-func (b *Builtin) Inspect() string { return "bultiin function"}
+func (b *Builtin) Inspect() string {
+	if b.Name == "" {
+		return "builtin function"
+	}
+	return "builtin " + b.Name
+}
 func (b *Builtin) Type()    ObjectType { return Bulitin_OBJ }
 
 type Array struct {
@@ -132,43 +222,111 @@ type Array struct {
 }
 
 func (a *Array) Type() ObjectType { return ARRAY_OBJ }
-func (a *Array) Inspect() string {
-	var out bytes.Buffer
+func (a *Array) Inspect() string { return Repr(a) }
 
-	elements := []string{}
-	for _, e := range a.Elements {
-		elements = append(elements, e.Inspect())
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash maps keys to values and remembers the order keys were first set in,
+// which is the order it is iterated and printed in. Keys are found by their
+// HashKey and then compared with Equals, so keys whose hashes collide are
+// kept apart. The zero Hash is empty and ready to use.
+type Hash struct {
+	buckets map[HashKey][]HashPair // pairs by the HashKey of their key
+	keys    []Object               // keys in insertion order
+}
+
+func NewHash() *Hash {
+	return &Hash{buckets: make(map[HashKey][]HashPair)}
+}
+
+// find returns the HashKey of key and the position of its pair in the
+// bucket, or -1 if the hash does not hold it. ok is false when key is not
+// hashable.
+func (h *Hash) find(key Object) (hashKey HashKey, index int, ok bool) {
+	if !IsHashable(key) {
+		return HashKey{}, -1, false
 	}
+	hashKey = key.(Hashable).HashKey()
+	for i, pair := range h.buckets[hashKey] {
+		if Equals(pair.Key, key) {
+			return hashKey, i, true
+		}
+	}
+	return hashKey, -1, true
+}
 
-	out.WriteString("[")
-	out.WriteString(fmt.Sprintf("%s", elements))
-	out.WriteString("]")
+// Set stores value under key, for which IsHashable must hold. A new key goes after
+// the existing ones; setting an existing key keeps its position.
+func (h *Hash) Set(key, value Object) {
+	hashKey, index, ok := h.find(key)
+	if !ok {
+		panic("unusable as hash key: " + string(key.Type()))
+	}
+	if h.buckets == nil {
+		h.buckets = make(map[HashKey][]HashPair)
+	}
 
-	return out.String()
+	if index >= 0 {
+		h.buckets[hashKey][index].Value = value
+		return
+	}
+	h.buckets[hashKey] = append(h.buckets[hashKey], HashPair{Key: key, Value: value})
+	h.keys = append(h.keys, key)
 }
 
-type HashPair struct {
-	Key   Object
-	Value Object
+// Get returns the pair stored under key. Keys that are not hashable are
+// never found.
+func (h *Hash) Get(key Object) (HashPair, bool) {
+	hashKey, index, _ := h.find(key)
+	if index < 0 {
+		return HashPair{}, false
+	}
+	return h.buckets[hashKey][index], true
 }
 
-type Hash struct {
-	Pairs map[HashKey]HashPair
+func (h *Hash) Delete(key Object) {
+	hashKey, index, _ := h.find(key)
+	if index < 0 {
+		return
+	}
+
+	bucket := h.buckets[hashKey]
+	if len(bucket) == 1 {
+		delete(h.buckets, hashKey)
+	} else {
+		h.buckets[hashKey] = append(bucket[:index:index], bucket[index+1:]...)
+	}
+
+	for i, k := range h.keys {
+		if Equals(k, key) {
+			h.keys = append(h.keys[:i:i], h.keys[i+1:]...)
+			break
+		}
+	}
 }
 
-func (h *Hash) Type() ObjectType { return HASH_OBJ }
-func (h *Hash) Inspect() string {
-	var out bytes.Buffer
+func (h *Hash) Len() int { return len(h.keys) }
 
-	pairs := []string{}
-	for _, pair := range h.Pairs {
-		pairs = append(pairs, fmt.Sprintf("%s: %s",
-			pair.Key.Inspect(), pair.Value.Inspect()))
+// Entries returns the pairs in insertion order.
+func (h *Hash) Entries() []HashPair {
+	pairs := make([]HashPair, len(h.keys))
+	for i, key := range h.keys {
+		pairs[i], _ = h.Get(key)
 	}
+	return pairs
+}
 
-	out.WriteString("{")
-	out.WriteString(fmt.Sprintf("%s", pairs))
-	out.WriteString("}")
+// Copy returns a hash with the same pairs in the same order.
+func (h *Hash) Copy() *Hash {
+	result := NewHash()
+	for _, pair := range h.Entries() {
+		result.Set(pair.Key, pair.Value)
+	}
+	return result
+}
 
-	return out.String()
-}
\ No newline at end of file
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+func (h *Hash) Inspect() string  { return Repr(h) }
\ No newline at end of file
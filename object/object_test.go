@@ -1,6 +1,9 @@
 package object
 
-import "testing"
+import (
+	"math"
+	"testing"
+)
 
 func TestStringHashKey(t *testing.T) {
 	hello1 := &String{Value: "Hello World"}
@@ -57,4 +60,361 @@ func TestIntegerHashKey(t *testing.T) {
 	if one1.HashKey() == two1.HashKey() {
 		t.Errorf("integers with twoerent content have same hash keys")
 	}
-}
\ No newline at end of file
+}
+func TestTupleHashKey(t *testing.T) {
+	tuple := func(elements ...Object) *Tuple { return &Tuple{Elements: elements} }
+	one, a := &Integer{Value: 1}, &String{Value: "a"}
+
+	if tuple(one, a).HashKey() != tuple(&Integer{Value: 1}, &String{Value: "a"}).HashKey() {
+		t.Errorf("tuples with same content have different hash keys")
+	}
+	if tuple(one, a).HashKey() == tuple(a, one).HashKey() {
+		t.Errorf("tuples with different order have same hash keys")
+	}
+	if tuple(tuple(one), a).HashKey() == tuple(one, a).HashKey() {
+		t.Errorf("nested tuple has same hash key as flat tuple")
+	}
+
+	if !IsHashable(tuple(one, tuple(a))) {
+		t.Errorf("tuple of hashable elements is not hashable")
+	}
+	if IsHashable(tuple(one, &Array{})) || IsHashable(&Array{}) {
+		t.Errorf("array is hashable")
+	}
+}
+
+func TestFloatHashKey(t *testing.T) {
+	one := &Integer{Value: 1}
+
+	if !IsHashable(&Float{Value: 1}) || (&Float{Value: 1}).HashKey() != one.HashKey() {
+		t.Errorf("whole float does not hash like the equal integer")
+	}
+	if (&Tuple{Elements: []Object{&Float{Value: 1}}}).HashKey() != (&Tuple{Elements: []Object{one}}).HashKey() {
+		t.Errorf("tuple of whole float does not hash like tuple of integer")
+	}
+	for _, value := range []float64{1.5, 1 << 53, math.Inf(1), math.NaN()} {
+		if IsHashable(&Float{Value: value}) {
+			t.Errorf("float %g is hashable", value)
+		}
+	}
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{1.5, "1.5"},
+		{2, "2.0"},
+		{-0.25, "-0.25"},
+		{1e21, "1e+21"},
+		{1e-7, "1e-07"},
+		{math.Inf(1), "+Inf"},
+		{math.NaN(), "NaN"},
+	}
+
+	for _, tt := range tests {
+		if got := (&Float{Value: tt.value}).Inspect(); got != tt.expected {
+			t.Errorf("Inspect of %g wrong. want=%q, got=%q", tt.value, tt.expected, got)
+		}
+	}
+}
+
+func TestRepr(t *testing.T) {
+	str := func(v string) *String { return &String{Value: v} }
+	one := &Integer{Value: 1}
+
+	hash := NewHash()
+	hash.Set(str("b"), &Array{Elements: []Object{str("x")}})
+	hash.Set(&Tuple{Elements: []Object{one}}, &Null{})
+
+	cyclic := &Array{Elements: []Object{one}}
+	cyclic.Elements = append(cyclic.Elements, cyclic)
+
+	cyclicHash := NewHash()
+	cyclicHash.Set(str("self"), cyclicHash)
+
+	tests := []struct {
+		obj      Object
+		expected string
+	}{
+		{str("a"), `"a"`},
+		{str("say \"hi\"\n\t\\"), `"say \"hi\"\n\t\\"`},
+		{&Array{Elements: []Object{one, str("a"), &Boolean{Value: true}}}, `[1, "a", true]`},
+		{&Array{}, "[]"},
+		{hash, `{"b": ["x"], (1,): null}`},
+		{NewHash(), "{}"},
+		{&Tuple{Elements: []Object{str("a"), one}}, `("a", 1)`},
+		{&Result{Ok: false, Value: str("bad")}, `err("bad")`},
+		{cyclic, "[1, [...]]"},
+		{&Array{Elements: []Object{cyclic, cyclic}}, "[[1, [...]], [1, [...]]]"},
+		{cyclicHash, `{"self": {...}}`},
+		{&Builtin{Name: "len"}, "builtin len"},
+		{&Builtin{}, "builtin function"},
+	}
+
+	for _, tt := range tests {
+		if got := Repr(tt.obj); got != tt.expected {
+			t.Errorf("Repr wrong. want=%s, got=%s", tt.expected, got)
+		}
+	}
+
+	if str("a").Inspect() != "a" {
+		t.Errorf("String.Inspect quotes at the top level. got=%s", str("a").Inspect())
+	}
+}
+
+func TestHashIteratorOrder(t *testing.T) {
+	hash := NewHash()
+	keys := []Object{
+		&String{Value: "b"},
+		&Integer{Value: 2},
+		&String{Value: "a"},
+		&Integer{Value: -1},
+		&Boolean{Value: true},
+	}
+	for _, key := range keys {
+		hash.Set(key, key)
+	}
+	hash.Set(keys[0], keys[0])
+	hash.Delete(&Integer{Value: 2})
+	hash.Set(keys[1], keys[1])
+
+	expected := []string{"b", "a", "-1", "true", "2"}
+
+	iter := hash.Iterator()
+	for i, want := range expected {
+		key, value, ok := iter.Next()
+		if !ok {
+			t.Fatalf("iterator exhausted after %d pairs", i)
+		}
+		if key.Inspect() != want || value != key {
+			t.Errorf("pair %d wrong. want key %s, got=%s", i, want, key.Inspect())
+		}
+	}
+
+	if _, _, ok := iter.Next(); ok {
+		t.Errorf("iterator not exhausted after %d pairs", len(expected))
+	}
+}
+
+// collideStrings makes every String key hash to one of three values until
+// the returned function is called.
+func collideStrings() func() {
+	saved := hashString
+	hashString = func(value string) uint64 { return uint64(len(value) % 3) }
+	return func() { hashString = saved }
+}
+
+func TestHashCollisions(t *testing.T) {
+	defer collideStrings()()
+
+	a, b := &String{Value: "ab"}, &String{Value: "cd"}
+	if a.HashKey() != b.HashKey() {
+		t.Fatalf("keys do not collide")
+	}
+
+	hash := NewHash()
+	hash.Set(a, &Integer{Value: 1})
+	hash.Set(b, &Integer{Value: 2})
+	hash.Set(&String{Value: "ab"}, &Integer{Value: 3})
+
+	if hash.Len() != 2 {
+		t.Fatalf("hash has wrong num of pairs. got=%d", hash.Len())
+	}
+	if pair, ok := hash.Get(a); !ok || pair.Value.Inspect() != "3" {
+		t.Errorf("wrong value for %s. got=%+v", a.Inspect(), pair)
+	}
+	if pair, ok := hash.Get(b); !ok || pair.Value.Inspect() != "2" {
+		t.Errorf("wrong value for %s. got=%+v", b.Inspect(), pair)
+	}
+
+	hash.Delete(a)
+	if _, ok := hash.Get(a); ok {
+		t.Errorf("%s still found after Delete", a.Inspect())
+	}
+	if _, ok := hash.Get(b); !ok {
+		t.Errorf("%s lost after deleting %s", b.Inspect(), a.Inspect())
+	}
+}
+
+// FuzzHashCollisions checks Hash against a Go map while all String keys
+// collide into a few buckets.
+func FuzzHashCollisions(f *testing.F) {
+	defer collideStrings()()
+
+	f.Add("a", "b", "ab", "b")
+	f.Add("", "xyz", "", "abc")
+	f.Add("é", "ü", "é", "é")
+
+	f.Fuzz(func(t *testing.T, k1, k2, k3, deleted string) {
+		hash := NewHash()
+		model := map[string]int64{}
+		order := []string{}
+
+		for i, key := range []string{k1, k2, k3} {
+			if _, ok := model[key]; !ok {
+				order = append(order, key)
+			}
+			model[key] = int64(i)
+			hash.Set(&String{Value: key}, &Integer{Value: int64(i)})
+		}
+
+		check := func() {
+			if hash.Len() != len(model) {
+				t.Fatalf("hash has wrong num of pairs. want=%d, got=%d", len(model), hash.Len())
+			}
+			for i, pair := range hash.Entries() {
+				key := pair.Key.(*String).Value
+				if key != order[i] {
+					t.Errorf("pair %d has wrong key. want=%q, got=%q", i, order[i], key)
+				}
+				if pair.Value.(*Integer).Value != model[key] {
+					t.Errorf("wrong value for %q. want=%d, got=%d", key, model[key], pair.Value.(*Integer).Value)
+				}
+			}
+			for key, value := range model {
+				pair, ok := hash.Get(&String{Value: key})
+				if !ok || pair.Value.(*Integer).Value != value {
+					t.Errorf("Get(%q) wrong. want=%d, got=%+v", key, value, pair)
+				}
+			}
+		}
+
+		check()
+
+		hash.Delete(&String{Value: deleted})
+		if _, ok := model[deleted]; ok {
+			delete(model, deleted)
+			for i, key := range order {
+				if key == deleted {
+					order = append(order[:i], order[i+1:]...)
+					break
+				}
+			}
+		}
+		if _, ok := hash.Get(&String{Value: deleted}); ok {
+			t.Errorf("Get(%q) found a deleted key", deleted)
+		}
+
+		check()
+	})
+}
+
+func TestEquals(t *testing.T) {
+	hash := func(key, value Object) *Hash {
+		hash := NewHash()
+		hash.Set(key, value)
+		return hash
+	}
+	array := func(elements ...Object) *Array { return &Array{Elements: elements} }
+	one, two := &Integer{Value: 1}, &Integer{Value: 2}
+	fn := &Builtin{}
+
+	tests := []struct {
+		a, b     Object
+		expected bool
+	}{
+		{one, &Integer{Value: 1}, true},
+		{one, two, false},
+		{&String{Value: "a"}, &String{Value: "a"}, true},
+		{&Boolean{Value: true}, &Boolean{Value: true}, true},
+		{&Null{}, &Null{}, true},
+		{one, &String{Value: "1"}, false},
+		{array(one, array(two)), array(&Integer{Value: 1}, array(&Integer{Value: 2})), true},
+		{array(one, two), array(two, one), false},
+		{array(one), array(one, two), false},
+		{hash(&String{Value: "k"}, array(one)), hash(&String{Value: "k"}, array(one)), true},
+		{hash(&String{Value: "k"}, one), hash(&String{Value: "k"}, two), false},
+		{hash(&String{Value: "k"}, one), hash(&String{Value: "j"}, one), false},
+		{&Range{Start: 0, End: 3, Step: 1}, &Range{Start: 0, End: 2, Step: 1, Inclusive: true}, true},
+		{&Range{Start: 5, End: 0, Step: 1}, &Range{Start: 1, End: 1, Step: 1}, true},
+		{&Range{Start: 0, End: 4, Step: 2}, &Range{Start: 0, End: 4, Step: 1}, false},
+		{one, &Float{Value: 1}, true},
+		{&Float{Value: 2.5}, two, false},
+		{array(one, two), array(&Float{Value: 1}, &Float{Value: 2}), true},
+		{fn, fn, true},
+		{fn, &Builtin{}, false},
+	}
+
+	cyclic := array(one)
+	cyclic.Elements = append(cyclic.Elements, cyclic)
+	otherCyclic := array(one)
+	otherCyclic.Elements = append(otherCyclic.Elements, otherCyclic)
+	cyclicHash := NewHash()
+	cyclicHash.Set(&String{Value: "self"}, cyclicHash)
+	tests = append(tests, []struct {
+		a, b     Object
+		expected bool
+	}{
+		{cyclic, cyclic, true},
+		{cyclic, otherCyclic, true},
+		{cyclic, array(one, array(one)), false},
+		{cyclicHash, cyclicHash, true},
+	}...)
+
+	for i, tt := range tests {
+		if got := Equals(tt.a, tt.b); got != tt.expected {
+			t.Errorf("tests[%d] - Equals(%s, %s) wrong. want=%t, got=%t",
+				i, tt.a.Inspect(), tt.b.Inspect(), tt.expected, got)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	integer := func(v int64) *Integer { return &Integer{Value: v} }
+	str := func(v string) *String { return &String{Value: v} }
+	array := func(elements ...Object) *Array { return &Array{Elements: elements} }
+
+	tests := []struct {
+		a, b       Object
+		expected   int
+		comparable bool
+	}{
+		{integer(1), integer(2), -1, true},
+		{integer(2), integer(2), 0, true},
+		{integer(3), integer(-3), 1, true},
+		{str("abc"), str("abd"), -1, true},
+		{str("b"), str("abc"), 1, true},
+		{str(""), str(""), 0, true},
+		{array(integer(1), integer(2)), array(integer(1), integer(3)), -1, true},
+		{array(integer(1), integer(2)), array(integer(1)), 1, true},
+		{array(), array(), 0, true},
+		{array(str("a"), integer(9)), array(str("b")), -1, true},
+		{integer(1), str("1"), 0, false},
+		{&Boolean{Value: false}, &Boolean{Value: true}, 0, false},
+		{array(integer(1)), array(str("1")), 0, false},
+		{&Float{Value: 1.5}, &Float{Value: 2}, -1, true},
+		{&Float{Value: math.NaN()}, &Float{Value: 1}, 0, false},
+		{integer(2), &Float{Value: 1.5}, 1, true},
+		{&Float{Value: 3}, integer(3), 0, true},
+		{integer(1), &Float{Value: math.NaN()}, 0, false},
+		{&Tuple{Elements: []Object{integer(1)}}, &Tuple{Elements: []Object{integer(0), integer(5)}}, 1, true},
+	}
+
+	cyclic := array(integer(1))
+	cyclic.Elements = append(cyclic.Elements, cyclic)
+	bigger := array(integer(2))
+	bigger.Elements = append(bigger.Elements, bigger)
+	tests = append(tests, []struct {
+		a, b       Object
+		expected   int
+		comparable bool
+	}{
+		{cyclic, cyclic, 0, true},
+		{cyclic, bigger, -1, true},
+	}...)
+
+	for i, tt := range tests {
+		result, ok := Compare(tt.a, tt.b)
+		if ok != tt.comparable {
+			t.Errorf("tests[%d] - Compare(%s, %s) comparable wrong. want=%t, got=%t",
+				i, tt.a.Inspect(), tt.b.Inspect(), tt.comparable, ok)
+			continue
+		}
+		if result != tt.expected {
+			t.Errorf("tests[%d] - Compare(%s, %s) wrong. want=%d, got=%d",
+				i, tt.a.Inspect(), tt.b.Inspect(), tt.expected, result)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package object
+
+import (
+	"fmt"
+	"math"
+)
+
+// Range is the lazy integer sequence produced by start..end (end excluded)
+// and start..=end (end included). Elements are computed on demand, so a
+// range never materialises more than the element being looked at.
+type Range struct {
+	Start     int64
+	End       int64
+	Step      int64 // never zero
+	Inclusive bool
+}
+
+func (r *Range) Type() ObjectType { return RANGE_OBJ }
+func (r *Range) Inspect() string {
+	op := ".."
+	if r.Inclusive {
+		op = "..="
+	}
+	if r.Step != 1 {
+		return fmt.Sprintf("%d%s%d step %d", r.Start, op, r.End, r.Step)
+	}
+	return fmt.Sprintf("%d%s%d", r.Start, op, r.End)
+}
+
+// Len returns the number of elements in the range. The span is computed
+// in uint64 so that ranges spanning most of int64 do not overflow; a range
+// with more than MaxInt64 elements reports MaxInt64.
+func (r *Range) Len() int64 {
+	low, high := r.Start, r.End
+	if r.Step < 0 {
+		low, high = high, low
+	}
+	if high < low || high == low && !r.Inclusive {
+		return 0
+	}
+
+	span, step := uint64(high)-uint64(low), r.stepSize()
+	if !r.Inclusive {
+		span--
+	}
+	count := span / step
+	if count >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(count) + 1
+}
+
+// stepSize returns the absolute value of the step.
+func (r *Range) stepSize() uint64 {
+	if r.Step < 0 {
+		return -uint64(r.Step)
+	}
+	return uint64(r.Step)
+}
+
+// At returns the i-th element of the range. The caller checks that i is
+// within [0, Len()); the element then fits in an int64 even if i*Step
+// alone wraps around.
+func (r *Range) At(i int64) int64 {
+	return r.Start + i*r.Step
+}
+
+// Contains reports whether v is one of the elements of the range.
+func (r *Range) Contains(v int64) bool {
+	var offset uint64
+	if r.Step > 0 {
+		if v < r.Start || v > r.End || v == r.End && !r.Inclusive {
+			return false
+		}
+		offset = uint64(v) - uint64(r.Start)
+	} else {
+		if v > r.Start || v < r.End || v == r.End && !r.Inclusive {
+			return false
+		}
+		offset = uint64(r.Start) - uint64(v)
+	}
+	return offset%r.stepSize() == 0
+}
+
+// ToArray materialises the range. The caller checks that Len is small
+// enough to hold every element in memory.
+func (r *Range) ToArray() *Array {
+	length := r.Len()
+	elements := make([]Object, length)
+	for i := int64(0); i < length; i++ {
+		elements[i] = &Integer{Value: r.At(i)}
+	}
+	return &Array{Elements: elements}
+}
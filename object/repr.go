@@ -0,0 +1,93 @@
+package object
+
+import "strings"
+
+// Repr returns obj the way it would be written in Monkey source: strings
+// are quoted and escaped, and containers print their elements the same way,
+// so a value with a literal syntax reads back as an equal value. A container
+// that contains itself prints as [...], (...) or {...} where it recurs.
+func Repr(obj Object) string {
+	p := &printer{visiting: map[Object]bool{}}
+	p.print(obj)
+	return p.out.String()
+}
+
+type printer struct {
+	out      strings.Builder
+	visiting map[Object]bool // containers being printed, to detect cycles
+}
+
+func (p *printer) print(obj Object) {
+	switch obj := obj.(type) {
+	case *String:
+		p.out.WriteString(quote(obj.Value))
+	case *Array:
+		p.container(obj, "[", "]", func() {
+			p.list(obj.Elements)
+		})
+	case *Tuple:
+		p.container(obj, "(", ")", func() {
+			p.list(obj.Elements)
+			if len(obj.Elements) == 1 {
+				p.out.WriteString(",")
+			}
+		})
+	case *Hash:
+		p.container(obj, "{", "}", func() {
+			for i, pair := range obj.Entries() {
+				if i > 0 {
+					p.out.WriteString(", ")
+				}
+				p.print(pair.Key)
+				p.out.WriteString(": ")
+				p.print(pair.Value)
+			}
+		})
+	case *Result:
+		if obj.Ok {
+			p.out.WriteString("ok(")
+		} else {
+			p.out.WriteString("err(")
+		}
+		p.print(obj.Value)
+		p.out.WriteString(")")
+	default:
+		p.out.WriteString(obj.Inspect())
+	}
+}
+
+// container prints a container between open and close, or open...close if
+// it is already being printed further up.
+func (p *printer) container(obj Object, open, close string, elements func()) {
+	p.out.WriteString(open)
+	if p.visiting[obj] {
+		p.out.WriteString("...")
+	} else {
+		p.visiting[obj] = true
+		elements()
+		delete(p.visiting, obj)
+	}
+	p.out.WriteString(close)
+}
+
+func (p *printer) list(elements []Object) {
+	for i, el := range elements {
+		if i > 0 {
+			p.out.WriteString(", ")
+		}
+		p.print(el)
+	}
+}
+
+var quoteReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\t", `\t`,
+	"\r", `\r`,
+)
+
+// quote returns s as a string literal, using the escapes the lexer reads.
+func quote(s string) string {
+	return `"` + quoteReplacer.Replace(s) + `"`
+}
@@ -0,0 +1,53 @@
+package object
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+)
+
+// Tuple is an immutable sequence written (a, b). Unlike an array it hashes
+// by value, so a tuple whose elements are all hashable can be a hash key.
+type Tuple struct {
+	Elements []Object
+}
+
+func (t *Tuple) Type() ObjectType { return TUPLE_OBJ }
+func (t *Tuple) Inspect() string  { return Repr(t) }
+
+// HashKey combines the hash keys of the elements, so tuples that are Equal
+// hash alike. Only call it on tuples for which IsHashable holds.
+func (t *Tuple) HashKey() HashKey {
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, el := range t.Elements {
+		key := el.(Hashable).HashKey()
+		h.Write([]byte(key.Field))
+		binary.LittleEndian.PutUint64(buf[:], key.Value)
+		h.Write(buf[:])
+	}
+	return HashKey{Field: t.Type(), Value: h.Sum64()}
+}
+
+func (t *Tuple) Iterator() Iterator {
+	return &arrayIterator{array: &Array{Elements: t.Elements}}
+}
+
+// IsHashable reports whether obj can be used as a hash key: integers,
+// booleans, strings and whole floats can, and tuples can when all their
+// elements can.
+func IsHashable(obj Object) bool {
+	switch obj := obj.(type) {
+	case *Tuple:
+		for _, el := range obj.Elements {
+			if !IsHashable(el) {
+				return false
+			}
+		}
+		return true
+	case *Float:
+		return obj.hashable()
+	case Hashable:
+		return true
+	}
+	return false
+}
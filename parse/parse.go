@@ -11,13 +11,18 @@ import (
 const (
 	_ int = iota
 	LOWEST
+	ASSIGN      // = or +=
+	PIPE        // a |> f
+	TERNARY     // a ? b : c
+	NULLISH     // a ?? b
 	EQUALS      // ==
-	LESSGREATER // > or <
+	LESSGREATER // > or <, >= or <=
+	RANGE       // 0..10
 	SUM         // +
 	PRODUCT     // *
 	PREFIX      // -X or !X
 	CALL        // myFunction(X
-	INDEX       // array[index
+	INDEX       // array[index, or result?
 )
 
 type (
@@ -26,16 +31,32 @@ type (
 )
 
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.LPAREN:   CALL,
-	token.LBRACKET: INDEX,
+	token.ASSIGN:          ASSIGN,
+	token.PLUS_ASSIGN:     ASSIGN,
+	token.MINUS_ASSIGN:    ASSIGN,
+	token.ASTERISK_ASSIGN: ASSIGN,
+	token.SLASH_ASSIGN:    ASSIGN,
+	token.PIPE:            PIPE,
+	token.QUESTION:        TERNARY,
+	token.NULLISH:         NULLISH,
+	token.EQ:              EQUALS,
+	token.NOT_EQ:          EQUALS,
+	token.LT:              LESSGREATER,
+	token.GT:              LESSGREATER,
+	token.LT_EQ:           LESSGREATER,
+	token.GT_EQ:           LESSGREATER,
+	token.IN:              LESSGREATER,
+	token.RANGE:           RANGE,
+	token.RANGE_INCLUSIVE: RANGE,
+	token.PLUS:            SUM,
+	token.MINUS:           SUM,
+	token.SLASH:           PRODUCT,
+	token.ASTERISK:        PRODUCT,
+	token.LPAREN:          CALL,
+	token.LBRACKET:        INDEX,
+	token.OPTIONAL_CHAIN:  INDEX,
+	token.PROPAGATE:       INDEX,
+	token.DOT:             INDEX,
 }
 
 type Parser struct {
@@ -47,6 +68,11 @@ type Parser struct {
 
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
+
+	// noArrowFunctions is set while parsing a match pattern or guard, where
+	// a trailing `x =>` or `(a, b) =>` starts the arm body rather than an
+	// arrow function
+	noArrowFunctions bool
 }
 
 func New(l *lexer.Lexer) *Parser {
@@ -59,6 +85,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
@@ -69,18 +96,36 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
+	p.registerPrefix(token.TRY, p.parseTryExpression)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfix(token.EQ, p.parseInfixExpression)
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.LT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.GT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.IN, p.parseInfixExpression)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
 	p.registerInfix(token.MINUS, p.parseInfixExpression)
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
 	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.PIPE, p.parsePipeExpression)
+	p.registerInfix(token.QUESTION, p.parseConditionalExpression)
+	p.registerInfix(token.PROPAGATE, p.parsePropagateExpression)
+	p.registerInfix(token.NULLISH, p.parseInfixExpression)
+	p.registerInfix(token.OPTIONAL_CHAIN, p.parseOptionalChain)
+	p.registerInfix(token.DOT, p.parsePropertyExpression)
+	p.registerInfix(token.RANGE, p.parseRangeExpression)
+	p.registerInfix(token.RANGE_INCLUSIVE, p.parseRangeExpression)
+	p.registerInfix(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PLUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.MINUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.ASTERISK_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.SLASH_ASSIGN, p.parseAssignExpression)
 
 	return p
 }
@@ -122,6 +167,25 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.THROW:
+		return p.parseThrowStatement()
+	case token.IMPORT:
+		return p.parseImportStatement()
+	case token.EXPORT:
+		return p.parseExportStatement()
+	case token.WHILE:
+		return p.parseWhileStatement()
+	case token.FOR:
+		return p.parseForStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
+	case token.FUNCTION:
+		if p.peekTokenIs(token.IDENT) {
+			return p.parseFunctionStatement()
+		}
+		return p.parseExpressionStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -149,6 +213,9 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 
 	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
 		infix := p.infixParseFns[p.peekToken.Type]
+		if p.peekIsNotIn() {
+			infix = p.parseNotInExpression
+		}
 		if infix == nil {
 			return leftExp
 		}
@@ -164,15 +231,104 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
 
 	p.nextToken() // skip [
+	if p.curTokenIs(token.COLON) {
+		return p.parseSliceExpression(exp.Token, left, nil)
+	}
 
 	exp.Index = p.parseExpression(LOWEST)
 
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		return p.parseSliceExpression(exp.Token, left, exp.Index)
+	}
+
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
 	}
 	return exp
 }
 
+// parseSliceExpression parses the rest of left[start:end:step] with the
+// current token being the first colon.
+func (p *Parser) parseSliceExpression(tok token.Token, left, start ast.Expression) ast.Expression {
+	exp := &ast.SliceExpression{Token: tok, Left: left, Start: start}
+
+	if !p.peekTokenIs(token.COLON) && !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken() // skip :
+		exp.End = p.parseExpression(LOWEST)
+	}
+
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		if !p.peekTokenIs(token.RBRACKET) {
+			p.nextToken() // skip :
+			exp.Step = p.parseExpression(LOWEST)
+		}
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+	return exp
+}
+
+// parseRangeExpression parses start..end and start..=end. A following
+// `step` identifier introduces the step, so step is not reserved as a
+// keyword and stays usable as a variable name.
+func (p *Parser) parseRangeExpression(start ast.Expression) ast.Expression {
+	exp := &ast.RangeExpression{
+		Token:     p.curToken,
+		Start:     start,
+		Inclusive: p.curTokenIs(token.RANGE_INCLUSIVE),
+	}
+
+	p.nextToken()
+	exp.End = p.parseExpression(RANGE)
+
+	if p.peekTokenIs(token.IDENT) && p.peekToken.Literal == "step" {
+		p.nextToken()
+		p.nextToken()
+		exp.Step = p.parseExpression(RANGE)
+	}
+
+	return exp
+}
+
+// parseOptionalChain parses a?.[index] and a?.name.
+func (p *Parser) parseOptionalChain(left ast.Expression) ast.Expression {
+	if p.peekTokenIs(token.LBRACKET) {
+		p.nextToken()
+		switch exp := p.parseIndexExpression(left).(type) {
+		case *ast.IndexExpression:
+			exp.Optional = true
+			return exp
+		case *ast.SliceExpression:
+			exp.Optional = true
+			return exp
+		default:
+			return nil
+		}
+	}
+
+	exp, ok := p.parsePropertyExpression(left).(*ast.PropertyExpression)
+	if !ok {
+		return nil
+	}
+	exp.Optional = true
+	return exp
+}
+
+func (p *Parser) parsePropertyExpression(left ast.Expression) ast.Expression {
+	exp := &ast.PropertyExpression{Token: p.curToken, Left: left}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	exp.Property = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	return exp
+}
+
 func (p *Parser) parseHashLiteral() ast.Expression {
 	hash := &ast.HashLiteral{Token: p.curToken}
 	hash.Pairs = make(map[ast.Expression]ast.Expression)
@@ -188,6 +344,7 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 		p.nextToken() // skip :
 		value := p.parseExpression(LOWEST)
 		hash.Pairs[key] = value
+		hash.Keys = append(hash.Keys, key)
 		
 		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
 			return nil
@@ -220,15 +377,117 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return stmt
 }
 
+func (p *Parser) parseThrowStatement() *ast.ThrowStatement {
+	stmt := &ast.ThrowStatement{Token: p.curToken}
+
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+// parseImportStatement parses both import forms. `as` and `from` are
+// recognised by position, so they remain usable as identifiers.
+func (p *Parser) parseImportStatement() ast.Statement {
+	stmt := &ast.ImportStatement{Token: p.curToken}
+
+	if p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		for !p.peekTokenIs(token.RBRACE) {
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			stmt.Names = append(stmt.Names, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+			if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+				return nil
+			}
+		}
+		p.nextToken() // skip }
+
+		if len(stmt.Names) == 0 {
+			p.errors = append(p.errors, "import list is empty")
+			return nil
+		}
+		if !p.expectContextualKeyword("from") || !p.expectPeek(token.STRING) {
+			return nil
+		}
+		stmt.Path = p.curToken.Literal
+	} else {
+		if !p.expectPeek(token.STRING) {
+			return nil
+		}
+		stmt.Path = p.curToken.Literal
+		if !p.expectContextualKeyword("as") || !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Alias = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+// expectContextualKeyword advances past the next token if it is the
+// identifier word.
+func (p *Parser) expectContextualKeyword(word string) bool {
+	if p.peekTokenIs(token.IDENT) && p.peekToken.Literal == word {
+		p.nextToken()
+		return true
+	}
+	p.errors = append(p.errors, fmt.Sprintf("expected next token to be %s, got %s instead",
+		word, p.peekToken.Literal))
+	return false
+}
+
+func (p *Parser) parseExportStatement() ast.Statement {
+	stmt := &ast.ExportStatement{Token: p.curToken}
+
+	p.nextToken()
+	switch {
+	case p.curTokenIs(token.LET):
+		let := p.parseLetStatement()
+		if let == nil {
+			return nil
+		}
+		stmt.Statement = let
+	case p.curTokenIs(token.FUNCTION) && p.peekTokenIs(token.IDENT):
+		function := p.parseFunctionStatement()
+		if function == nil {
+			return nil
+		}
+		stmt.Statement = function
+	default:
+		p.errors = append(p.errors, fmt.Sprintf("cannot export %s, only let and fn declarations", p.curToken.Literal))
+		return nil
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseLetStatement() *ast.LetStatement {
 	stmt := &ast.LetStatement{
 		Token: p.curToken,
 	}
-	if !p.expectPeek(token.IDENT) {
-		return nil
-	}
 
-	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	if p.peekTokenIs(token.LBRACKET) || p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		stmt.Pattern = p.parsePattern()
+		if stmt.Pattern == nil {
+			return nil
+		}
+	} else {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
 
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
@@ -238,6 +497,154 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 
 	stmt.Value = p.parseExpression(LOWEST)
 
+	if fl, ok := stmt.Value.(*ast.FunctionLiteral); ok && stmt.Name != nil {
+		fl.Name = stmt.Name.Value
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseFunctionStatement() ast.Statement {
+	stmt := &ast.FunctionStatement{Token: p.curToken}
+
+	p.nextToken() // skip fn
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	stmt.Function = &ast.FunctionLiteral{Token: stmt.Token, Name: stmt.Name.Value}
+	if !p.parseFunctionSignature(stmt.Function) {
+		return nil
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseWhileStatement() ast.Statement {
+	stmt := &ast.WhileStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	stmt.Body = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseForStatement() ast.Statement {
+	forToken := p.curToken
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken() // skip (
+	if p.curTokenIs(token.IDENT) && (p.peekTokenIs(token.IN) || p.peekTokenIs(token.COMMA)) {
+		return p.parseForInStatement(forToken)
+	}
+
+	stmt := &ast.ForStatement{Token: forToken}
+	if !p.curTokenIs(token.SEMICOLON) {
+		stmt.Init = p.parseStatement()
+		if !p.curTokenIs(token.SEMICOLON) {
+			p.peekError(token.SEMICOLON)
+			return nil
+		}
+	}
+
+	p.nextToken() // skip ;
+	if !p.curTokenIs(token.SEMICOLON) {
+		stmt.Condition = p.parseExpression(LOWEST)
+		if !p.expectPeek(token.SEMICOLON) {
+			return nil
+		}
+	}
+
+	p.nextToken() // skip ;
+	if !p.curTokenIs(token.RPAREN) {
+		stmt.Post = p.parseExpression(LOWEST)
+		if !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	stmt.Body = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseForInStatement(forToken token.Token) ast.Statement {
+	stmt := &ast.ForInStatement{Token: forToken}
+
+	stmt.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken() // skip identifier
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Key = stmt.Value
+		stmt.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	if !p.expectPeek(token.IN) {
+		return nil
+	}
+
+	p.nextToken() // skip in
+	stmt.Iterable = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	stmt.Body = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseBreakStatement() ast.Statement {
+	stmt := &ast.BreakStatement{Token: p.curToken}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseContinueStatement() ast.Statement {
+	stmt := &ast.ContinueStatement{Token: p.curToken}
+
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
@@ -269,7 +676,13 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 }
 
 func (p *Parser) parseIdentifier() ast.Expression {
-	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if p.peekTokenIs(token.ARROW) && !p.noArrowFunctions {
+		p.nextToken()
+		return p.parseArrowFunction([]ast.Expression{ident})
+	}
+	return ident
 }
 
 func (p *Parser) parseIntegerLiteral() ast.Expression {
@@ -285,22 +698,77 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	return lit
 }
 
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.curToken}
+
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		msg := fmt.Sprintf("could not parse %q as float", p.curToken.Literal)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+	lit.Value = value
+	return lit
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{Token: p.curToken, Value: p.curTokenIs(token.TRUE)}
 }
 
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	tok := p.curToken
+
+	// () => body, or the empty tuple ()
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return p.parseTupleOrArrowFunction(tok, []ast.Expression{})
+	}
+
 	p.nextToken()
 
 	expression := p.parseExpression(LOWEST)
 
+	// (a, b) => body, or the tuple (a, b); (a,) is a tuple of one
+	if p.peekTokenIs(token.COMMA) {
+		elements := []ast.Expression{expression}
+		for p.peekTokenIs(token.COMMA) {
+			p.nextToken() // skip curr token
+			if p.peekTokenIs(token.RPAREN) {
+				break
+			}
+			p.nextToken() // skip comma
+			elements = append(elements, p.parseExpression(LOWEST))
+		}
+
+		if !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+		return p.parseTupleOrArrowFunction(tok, elements)
+	}
+
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
 
+	// (a) => body
+	if p.peekTokenIs(token.ARROW) && !p.noArrowFunctions {
+		p.nextToken()
+		return p.parseArrowFunction([]ast.Expression{expression})
+	}
+
 	return expression
 }
 
+// parseTupleOrArrowFunction finishes a parenthesised list whose closing )
+// is the current token: an arrow function if => follows, a tuple otherwise.
+func (p *Parser) parseTupleOrArrowFunction(tok token.Token, elements []ast.Expression) ast.Expression {
+	if p.peekTokenIs(token.ARROW) && !p.noArrowFunctions {
+		p.nextToken()
+		return p.parseArrowFunction(elements)
+	}
+	return &ast.TupleLiteral{Token: tok, Elements: elements}
+}
+
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	expression := &ast.PrefixExpression{
 		Token:    p.curToken,
@@ -315,6 +783,9 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 }
 
 func (p *Parser) peekPrecedence() int {
+	if p.peekIsNotIn() {
+		return LESSGREATER
+	}
 	if p, ok := precedences[p.peekToken.Type]; ok {
 		return p
 	}
@@ -341,6 +812,96 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return expression
 }
 
+// peekIsNotIn reports whether the next tokens are `not in`. not is not a
+// keyword, like step, so it stays usable as a name everywhere else.
+func (p *Parser) peekIsNotIn() bool {
+	return p.peekTokenIs(token.IDENT) && p.peekToken.Literal == "not" &&
+		p.l.PeekToken().Type == token.IN
+}
+
+// parseNotInExpression parses `left not in right` into an infix expression
+// with the operator "not in".
+func (p *Parser) parseNotInExpression(left ast.Expression) ast.Expression {
+	expression := &ast.InfixExpression{
+		Token:    p.curToken,
+		Operator: "not in",
+		Left:     left,
+	}
+
+	if !p.expectPeek(token.IN) {
+		return nil
+	}
+
+	precedence := p.curPrecedence()
+	p.nextToken()
+	expression.Right = p.parseExpression(precedence)
+
+	return expression
+}
+
+func (p *Parser) parseAssignExpression(left ast.Expression) ast.Expression {
+	name, ok := left.(*ast.Identifier)
+	if !ok {
+		msg := fmt.Sprintf("cannot assign to %s", left.String())
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	expression := &ast.AssignExpression{
+		Token:    p.curToken,
+		Name:     name,
+		Operator: p.curToken.Literal,
+	}
+
+	// assignment is right associative: a = b = c is a = (b = c)
+	precedence := p.curPrecedence()
+	p.nextToken()
+	expression.Value = p.parseExpression(precedence - 1)
+	return expression
+}
+
+// parsePipeExpression desugars a |> f(b) into the call f(a, b), and a |> f
+// into f(a).
+func (p *Parser) parsePipeExpression(left ast.Expression) ast.Expression {
+	pipe := p.curToken
+
+	precedence := p.curPrecedence()
+	p.nextToken()
+	right := p.parseExpression(precedence)
+	if right == nil {
+		return nil
+	}
+
+	if call, ok := right.(*ast.CallExpression); ok {
+		arguments := append([]ast.Expression{left}, call.Arguments...)
+		return &ast.CallExpression{Token: pipe, Function: call.Function, Arguments: arguments}
+	}
+	return &ast.CallExpression{Token: pipe, Function: right, Arguments: []ast.Expression{left}}
+}
+
+// parsePropagateExpression parses the postfix propagation operator result?.
+// The lexer tells it apart from the ? of a ternary by the lack of white
+// space before it.
+func (p *Parser) parsePropagateExpression(left ast.Expression) ast.Expression {
+	return &ast.PropagateExpression{Token: p.curToken, Value: left}
+}
+
+func (p *Parser) parseConditionalExpression(condition ast.Expression) ast.Expression {
+	expression := &ast.ConditionalExpression{Token: p.curToken, Condition: condition}
+
+	p.nextToken() // skip ?
+	expression.Consequence = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+
+	// a ? b : c ? d : e is a ? b : (c ? d : e)
+	p.nextToken() // skip :
+	expression.Alternative = p.parseExpression(TERNARY - 1)
+	return expression
+}
+
 func (p *Parser) parseIfExpression() ast.Expression {
 	expression := &ast.IfExpression{Token: p.curToken}
 
@@ -362,6 +923,16 @@ func (p *Parser) parseIfExpression() ast.Expression {
 
 	if p.peekTokenIs(token.ELSE) {
 		p.nextToken()
+
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+			expression.Alternative = p.parseElseIf()
+			if expression.Alternative == nil {
+				return nil
+			}
+			return expression
+		}
+
 		if !p.expectPeek(token.LBRACE) {
 			return nil
 		}
@@ -371,6 +942,152 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return expression
 }
 
+func (p *Parser) parseTryExpression() ast.Expression {
+	expression := &ast.TryExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	expression.Block = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.CATCH) {
+		p.nextToken()
+
+		if p.peekTokenIs(token.LPAREN) {
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			expression.CatchParam = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			if !p.expectPeek(token.RPAREN) {
+				return nil
+			}
+		}
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		expression.Catch = p.parseBlockStatement()
+	}
+
+	if p.peekTokenIs(token.FINALLY) {
+		p.nextToken()
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		expression.Finally = p.parseBlockStatement()
+	}
+
+	if expression.Catch == nil && expression.Finally == nil {
+		p.errors = append(p.errors, "try without catch or finally")
+		return nil
+	}
+
+	return expression
+}
+
+// parseElseIf parses the `if` of an `else if` and wraps it in a block, so
+// that a chain evaluates exactly like the equivalent nested if-else.
+func (p *Parser) parseElseIf() *ast.BlockStatement {
+	block := &ast.BlockStatement{Token: p.curToken}
+
+	stmt := &ast.ExpressionStatement{Token: p.curToken}
+	stmt.Expression = p.parseIfExpression()
+	if stmt.Expression == nil {
+		return nil
+	}
+
+	block.Statements = []ast.Statement{stmt}
+	return block
+}
+
+func (p *Parser) parseMatchExpression() ast.Expression {
+	expression := &ast.MatchExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Subject = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken() // skip { or ,
+		arm := p.parseMatchArm()
+		if arm == nil {
+			return nil
+		}
+		expression.Arms = append(expression.Arms, arm)
+
+		// arms with a block body may omit the separating comma
+		if p.curTokenIs(token.RBRACE) && !p.peekTokenIs(token.COMMA) {
+			continue
+		}
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	return expression
+}
+
+func (p *Parser) parseMatchArm() *ast.MatchArm {
+	arm := &ast.MatchArm{Token: p.curToken}
+
+	arm.Patterns = append(arm.Patterns, p.parseMatchPattern())
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken() // skip pattern
+		p.nextToken() // skip comma
+		arm.Patterns = append(arm.Patterns, p.parseMatchPattern())
+	}
+
+	if p.peekTokenIs(token.IF) {
+		p.nextToken() // skip pattern
+		p.nextToken() // skip if
+		p.noArrowFunctions = true
+		arm.Guard = p.parseExpression(LOWEST)
+		p.noArrowFunctions = false
+	}
+
+	if !p.expectPeek(token.ARROW) {
+		return nil
+	}
+
+	p.nextToken() // skip =>
+	if p.curTokenIs(token.LBRACE) {
+		arm.Body = p.parseBlockStatement()
+		return arm
+	}
+
+	stmt := &ast.ExpressionStatement{Token: p.curToken}
+	stmt.Expression = p.parseExpression(LOWEST)
+	arm.Body = &ast.BlockStatement{Token: p.curToken, Statements: []ast.Statement{stmt}}
+	return arm
+}
+
+// parseMatchPattern parses a pattern of a match arm. A bare identifier is a
+// binding (or the `_` wildcard) and is never parsed as a larger expression.
+func (p *Parser) parseMatchPattern() ast.Expression {
+	if p.curTokenIs(token.IDENT) {
+		return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+	p.noArrowFunctions = true
+	pattern := p.parseExpression(LOWEST)
+	p.noArrowFunctions = false
+	return pattern
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{Token: p.curToken, Statements: []ast.Statement{}}
 
@@ -386,25 +1103,71 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 }
 
 func (p *Parser) parseFunctionLiteral() ast.Expression {
-	lit := &ast.FunctionLiteral{Token: p.curToken, Parameters: []*ast.Identifier{}}
+	lit := &ast.FunctionLiteral{Token: p.curToken, Parameters: []ast.Expression{}}
+
+	if !p.parseFunctionSignature(lit) {
+		return nil
+	}
+	return lit
+}
 
+// parseFunctionSignature parses the `(params) { body }` following fn or the
+// name of a function statement into lit.
+func (p *Parser) parseFunctionSignature(lit *ast.FunctionLiteral) bool {
 	// skip to (
 	if !p.expectPeek(token.LPAREN) {
-		return nil
+		return false
 	}
 
 	lit.Parameters = p.parseFuncitonParameters()
+	if lit.Parameters == nil {
+		return false
+	}
 
 	if !p.expectPeek(token.LBRACE) {
-		return nil 
+		return false
 	}
 
 	lit.Body = p.parseBlockStatement()
+	return true
+}
+
+// parseArrowFunction parses the body of `(params) => body` or `x => body`,
+// with the current token being the =>. An expression body is wrapped in a
+// block, so arrow functions are plain function literals.
+func (p *Parser) parseArrowFunction(params []ast.Expression) ast.Expression {
+	lit := &ast.FunctionLiteral{
+		Token:      token.Token{Type: token.FUNCTION, Literal: "fn"},
+		Parameters: []ast.Expression{},
+	}
+
+	for _, param := range params {
+		if param == nil {
+			return nil
+		}
+		ident, ok := param.(*ast.Identifier)
+		if !ok {
+			msg := fmt.Sprintf("invalid arrow function parameter: %s", param.String())
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		lit.Parameters = append(lit.Parameters, ident)
+	}
+
+	p.nextToken() // skip =>
+	if p.curTokenIs(token.LBRACE) {
+		lit.Body = p.parseBlockStatement()
+		return lit
+	}
+
+	stmt := &ast.ExpressionStatement{Token: p.curToken}
+	stmt.Expression = p.parseExpression(LOWEST)
+	lit.Body = &ast.BlockStatement{Token: stmt.Token, Statements: []ast.Statement{stmt}}
 	return lit
 }
 
-func (p *Parser) parseFuncitonParameters() []*ast.Identifier {
-	identifiers := make([]*ast.Identifier, 0)
+func (p *Parser) parseFuncitonParameters() []ast.Expression {
+	identifiers := make([]ast.Expression, 0)
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
@@ -413,13 +1176,21 @@ func (p *Parser) parseFuncitonParameters() []*ast.Identifier {
 
 	// skip '(
 	p.nextToken()
-	identifiers = append(identifiers, p.parseIdentifier().(*ast.Identifier))
+	param := p.parsePattern()
+	if param == nil {
+		return nil
+	}
+	identifiers = append(identifiers, param)
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken() // skip identifier
 		p.nextToken() // skip comma
 
-		identifiers = append(identifiers, p.parseIdentifier().(*ast.Identifier))
+		param := p.parsePattern()
+		if param == nil {
+			return nil
+		}
+		identifiers = append(identifiers, param)
 	}
 
 	if !p.expectPeek(token.RPAREN) {
@@ -429,6 +1200,89 @@ func (p *Parser) parseFuncitonParameters() []*ast.Identifier {
 	return identifiers
 }
 
+// parsePattern parses a binding target: an identifier, or an array or hash
+// pattern destructuring the bound value.
+func (p *Parser) parsePattern() ast.Expression {
+	switch p.curToken.Type {
+	case token.IDENT:
+		return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	case token.LBRACKET:
+		return p.parseArrayPattern()
+	case token.LBRACE:
+		return p.parseHashPattern()
+	default:
+		msg := fmt.Sprintf("unexpected %s in binding pattern", p.curToken.Type)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+}
+
+func (p *Parser) parseArrayPattern() ast.Expression {
+	pattern := &ast.ArrayPattern{Token: p.curToken}
+
+	for !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken() // skip [ or ,
+
+		if p.curTokenIs(token.ELLIPSIS) {
+			// the rest element has to be the last one
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			pattern.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			break
+		}
+
+		element := p.parsePattern()
+		if element == nil {
+			return nil
+		}
+		pattern.Elements = append(pattern.Elements, element)
+
+		if !p.peekTokenIs(token.RBRACKET) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+	return pattern
+}
+
+func (p *Parser) parseHashPattern() ast.Expression {
+	pattern := &ast.HashPattern{Token: p.curToken}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		key := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+		// {name} is shorthand for {name: name}
+		var value ast.Expression = key
+		if p.peekTokenIs(token.COLON) {
+			p.nextToken() // skip key
+			p.nextToken() // skip :
+			value = p.parsePattern()
+			if value == nil {
+				return nil
+			}
+		}
+
+		pattern.Keys = append(pattern.Keys, key)
+		pattern.Values = append(pattern.Values, value)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	return pattern
+}
+
 func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
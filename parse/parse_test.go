@@ -816,6 +816,1309 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 }
 
 
+func TestAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		name     string
+		operator string
+		value    interface{}
+	}{
+		{"x = 5;", "x", "=", 5},
+		{"x += 1;", "x", "+=", 1},
+		{"x -= y;", "x", "-=", "y"},
+		{"x *= 2;", "x", "*=", 2},
+		{"x /= 2;", "x", "/=", 2},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.AssignExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.AssignExpression. got=%T",
+				stmt.Expression)
+		}
+
+		if !testIdentifier(t, exp.Name, tt.name) {
+			return
+		}
+
+		if exp.Operator != tt.operator {
+			t.Fatalf("exp.Operator is not '%s'. got=%s", tt.operator, exp.Operator)
+		}
+
+		if !testLiteralExpression(t, exp.Value, tt.value) {
+			return
+		}
+	}
+}
+
+func TestAssignExpressionPrecedence(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a = b = c", "a = b = c"},
+		{"a = b + c * d", "a = (b + (c * d))"},
+		{"a += b == c", "a += (b == c)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp := stmt.Expression.(*ast.AssignExpression)
+		if _, ok := exp.Value.(*ast.AssignExpression); tt.input == "a = b = c" && !ok {
+			t.Errorf("assignment is not right associative. got=%T", exp.Value)
+		}
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestInvalidAssignTarget(t *testing.T) {
+	l := lexer.New("5 = 6;")
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 parser error. got=%d (%v)", len(errors), errors)
+	}
+
+	if errors[0] != "cannot assign to 5" {
+		t.Errorf("wrong error message. got=%q", errors[0])
+	}
+}
+
+func TestWhileStatement(t *testing.T) {
+	input := "while (x < 10) { x += 1; }"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d",
+			len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.WhileStatement. got=%T",
+			program.Statements[0])
+	}
+
+	if !testInfixExpression(t, stmt.Condition, "x", "<", 10) {
+		return
+	}
+
+	if len(stmt.Body.Statements) != 1 {
+		t.Fatalf("body is not 1 statement. got=%d", len(stmt.Body.Statements))
+	}
+
+	if stmt.String() != "while ((x < 10)) x += 1" {
+		t.Errorf("stmt.String() wrong. got=%q", stmt.String())
+	}
+}
+
+func TestForStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"for (let i = 0; i < 10; i += 1) { put(i); }",
+			"for (let i = 0; (i < 10); i += 1) put(i)",
+		},
+		{
+			"for (i = 0; i < 10; i += 1) { break; }",
+			"for (i = 0; (i < 10); i += 1) break;",
+		},
+		{
+			"for (;;) { continue; }",
+			"for (; ; ) continue;",
+		},
+		{
+			"for (; x;) { x -= 1 };",
+			"for (; x; ) x -= 1",
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d",
+				len(program.Statements))
+		}
+
+		if _, ok := program.Statements[0].(*ast.ForStatement); !ok {
+			t.Fatalf("program.Statements[0] is not ast.ForStatement. got=%T",
+				program.Statements[0])
+		}
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+
+func TestForInStatement(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedKey   string
+		expectedValue string
+		expected      string
+	}{
+		{"for (x in xs) { put(x); }", "", "x", "for (x in xs) put(x)"},
+		{"for (k, v in h) { put(k, v); }", "k", "v", "for (k, v in h) put(k, v)"},
+		{"for (c in \"abc\") {}", "", "c", "for (c in abc) "},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d",
+				len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ForInStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ForInStatement. got=%T",
+				program.Statements[0])
+		}
+
+		if tt.expectedKey == "" {
+			if stmt.Key != nil {
+				t.Errorf("stmt.Key was not nil. got=%+v", stmt.Key)
+			}
+		} else if !testIdentifier(t, stmt.Key, tt.expectedKey) {
+			return
+		}
+
+		if !testIdentifier(t, stmt.Value, tt.expectedValue) {
+			return
+		}
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+
+func TestElseIfExpression(t *testing.T) {
+	input := "if (x < y) { x } else if (x > y) { y } else { z }"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.IfExpression. got=%T",
+			stmt.Expression)
+	}
+
+	if !testInfixExpression(t, exp.Condition, "x", "<", "y") {
+		return
+	}
+
+	if exp.Alternative == nil || len(exp.Alternative.Statements) != 1 {
+		t.Fatalf("alternative is not 1 statement. got=%+v", exp.Alternative)
+	}
+
+	alternative, ok := exp.Alternative.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("alternative is not ast.ExpressionStatement. got=%T",
+			exp.Alternative.Statements[0])
+	}
+
+	elseIf, ok := alternative.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("alternative is not ast.IfExpression. got=%T",
+			alternative.Expression)
+	}
+
+	if !testInfixExpression(t, elseIf.Condition, "x", ">", "y") {
+		return
+	}
+
+	if elseIf.Alternative == nil {
+		t.Fatalf("else-if has no final else")
+	}
+
+	expected := "if(x < y) xelse if(x > y) yelse z"
+	if program.String() != expected {
+		t.Errorf("expected=%q, got=%q", expected, program.String())
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	input := `match (x) {
+		1, 2 => "small",
+		[a, b] => a + b,
+		{"name": n} if n > 0 => { n },
+		_ => "other"
+	}`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d",
+			len(program.Statements))
+	}
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.MatchExpression. got=%T",
+			stmt.Expression)
+	}
+
+	if !testIdentifier(t, exp.Subject, "x") {
+		return
+	}
+
+	if len(exp.Arms) != 4 {
+		t.Fatalf("match has wrong number of arms. want 4, got=%d", len(exp.Arms))
+	}
+
+	first := exp.Arms[0]
+	if len(first.Patterns) != 2 {
+		t.Fatalf("first arm has wrong number of patterns. got=%d", len(first.Patterns))
+	}
+	testIntegerLiteral(t, first.Patterns[0], 1)
+	testIntegerLiteral(t, first.Patterns[1], 2)
+
+	if _, ok := exp.Arms[1].Patterns[0].(*ast.ArrayLiteral); !ok {
+		t.Errorf("second arm pattern is not ast.ArrayLiteral. got=%T",
+			exp.Arms[1].Patterns[0])
+	}
+
+	third := exp.Arms[2]
+	if _, ok := third.Patterns[0].(*ast.HashLiteral); !ok {
+		t.Errorf("third arm pattern is not ast.HashLiteral. got=%T", third.Patterns[0])
+	}
+	if !testInfixExpression(t, third.Guard, "n", ">", 0) {
+		return
+	}
+
+	testIdentifier(t, exp.Arms[3].Patterns[0], "_")
+	if exp.Arms[3].Guard != nil {
+		t.Errorf("last arm has a guard. got=%+v", exp.Arms[3].Guard)
+	}
+}
+
+func TestMatchExpressionString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"match (x) { 1 => a }", "match x { 1 => a }"},
+		{"match (x) { 1 => { a } 2 => { b } }", "match x { 1 => a, 2 => b }"},
+		{"match (x) { n if n > 1 => n * 2, _ => 0, }", "match x { n if (n > 1) => (n * 2), _ => 0 }"},
+		{"match (x) { (5) => a, (1, 2) => b }", "match x { 5 => a, (1, 2) => b }"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+
+func TestDestructuringLetStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b] = arr;", "let [a, b] = arr;"},
+		{"let [a, ...rest] = arr;", "let [a, ...rest] = arr;"},
+		{"let [...all] = arr;", "let [...all] = arr;"},
+		{"let [] = arr;", "let [] = arr;"},
+		{"let [a, [b, c]] = arr;", "let [a, [b, c]] = arr;"},
+		{"let {name, age} = person;", "let {name, age} = person;"},
+		{"let {name: n, pets: [first]} = person;", "let {name: n, pets: [first]} = person;"},
+		{"let [{x}, {y: b}] = points;", "let [{x}, {y: b}] = points;"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d",
+				len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.LetStatement. got=%T",
+				program.Statements[0])
+		}
+
+		if stmt.Pattern == nil || stmt.Name != nil {
+			t.Fatalf("let statement does not destructure. got Name=%v, Pattern=%v",
+				stmt.Name, stmt.Pattern)
+		}
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestArrayPatternParsing(t *testing.T) {
+	input := "let [a, [b], ...rest] = arr;"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.LetStatement)
+	pattern, ok := stmt.Pattern.(*ast.ArrayPattern)
+	if !ok {
+		t.Fatalf("stmt.Pattern is not ast.ArrayPattern. got=%T", stmt.Pattern)
+	}
+
+	if len(pattern.Elements) != 2 {
+		t.Fatalf("pattern has wrong number of elements. got=%d", len(pattern.Elements))
+	}
+
+	testIdentifier(t, pattern.Elements[0], "a")
+
+	nested, ok := pattern.Elements[1].(*ast.ArrayPattern)
+	if !ok {
+		t.Fatalf("pattern.Elements[1] is not ast.ArrayPattern. got=%T", pattern.Elements[1])
+	}
+	testIdentifier(t, nested.Elements[0], "b")
+
+	testIdentifier(t, pattern.Rest, "rest")
+}
+
+func TestDestructuringParseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, 1] = arr;", "unexpected INT in binding pattern"},
+		{"let [...rest, a] = arr;", "expected next token to be ], got rest instead"},
+		{"let {1} = h;", "expected next token to be IDENT, got { instead"},
+		{"fn(a, 2) {}", "unexpected INT in binding pattern"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestFunctionParameterPatterns(t *testing.T) {
+	input := "fn([a, b], {name}, c) { a };"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	function := stmt.Expression.(*ast.FunctionLiteral)
+
+	if len(function.Parameters) != 3 {
+		t.Fatalf("function literal parameters wrong. want 3, got=%d",
+			len(function.Parameters))
+	}
+
+	if _, ok := function.Parameters[0].(*ast.ArrayPattern); !ok {
+		t.Errorf("parameter 0 is not ast.ArrayPattern. got=%T", function.Parameters[0])
+	}
+	if _, ok := function.Parameters[1].(*ast.HashPattern); !ok {
+		t.Errorf("parameter 1 is not ast.HashPattern. got=%T", function.Parameters[1])
+	}
+	testIdentifier(t, function.Parameters[2], "c")
+
+	expected := "fn([a, b], {name}, c) a"
+	if function.String() != expected {
+		t.Errorf("expected=%q, got=%q", expected, function.String())
+	}
+}
+
+
+func TestConditionalAndNullishParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a ? b : c", "(a ? b : c)"},
+		{"a < b ? a + 1 : b * 2", "((a < b) ? (a + 1) : (b * 2))"},
+		{"a ? b : c ? d : e", "(a ? b : (c ? d : e))"},
+		{"a ? b ? c : d : e", "(a ? (b ? c : d) : e)"},
+		{"x = a ? b : c", "x = (a ? b : c)"},
+		{"a ?? b", "(a ?? b)"},
+		{"a ?? b ?? c", "((a ?? b) ?? c)"},
+		{"a ?? b == c", "(a ?? (b == c))"},
+		{"a ?? b ? c : d", "((a ?? b) ? c : d)"},
+		{"a?.b", "(a?.b)"},
+		{"a?.[1 + 2]", "(a?.[(1 + 2)])"},
+		{"a?.b?.c", "((a?.b)?.c)"},
+		{"a?.b ?? c", "((a?.b) ?? c)"},
+		{"f(x)?.[0]", "(f(x)?.[0])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestConditionalExpression(t *testing.T) {
+	input := "x < y ? x : y"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.ConditionalExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.ConditionalExpression. got=%T",
+			stmt.Expression)
+	}
+
+	if !testInfixExpression(t, exp.Condition, "x", "<", "y") {
+		return
+	}
+	testIdentifier(t, exp.Consequence, "x")
+	testIdentifier(t, exp.Alternative, "y")
+}
+
+func TestOptionalIndexExpression(t *testing.T) {
+	input := "people?.[0]"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.IndexExpression. got=%T",
+			stmt.Expression)
+	}
+
+	if !exp.Optional {
+		t.Errorf("index expression is not optional")
+	}
+	testIdentifier(t, exp.Left, "people")
+	testIntegerLiteral(t, exp.Index, 0)
+}
+
+func TestPropertyExpression(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedLeft     string
+		expectedProperty string
+		optional         bool
+	}{
+		{"person.name", "person", "name", false},
+		{"person?.name", "person", "name", true},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.PropertyExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.PropertyExpression. got=%T",
+				stmt.Expression)
+		}
+
+		testIdentifier(t, exp.Left, tt.expectedLeft)
+		testIdentifier(t, exp.Property, tt.expectedProperty)
+
+		if exp.Optional != tt.optional {
+			t.Errorf("exp.Optional wrong. want=%t, got=%t", tt.optional, exp.Optional)
+		}
+	}
+}
+
+func TestMethodCallParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a.b", "(a.b)"},
+		{"a.b.c", "((a.b).c)"},
+		{"a.b(c)", "(a.b)(c)"},
+		{"a.b(c).d(e, f)", "((a.b)(c).d)(e, f)"},
+		{"-a.b", "(-(a.b))"},
+		{"a.b + c.d * e", "((a.b) + ((c.d) * e))"},
+		{"a[0].b", "((a[0]).b)"},
+		{"a.b[0]", "((a.b)[0])"},
+		{`"abc".upper()`, "(abc.upper)()"},
+		{"[1, 2].map(f)", "([1, 2].map)(f)"},
+		{"a?.b(c)", "(a?.b)(c)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestParsingSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a[1:2]", "(a[1:2])"},
+		{"a[1:]", "(a[1:])"},
+		{"a[:2]", "(a[:2])"},
+		{"a[:]", "(a[:])"},
+		{"a[::2]", "(a[::2])"},
+		{"a[::]", "(a[:])"},
+		{"a[1:2:3]", "(a[1:2:3])"},
+		{"a[-1:]", "(a[(-1):])"},
+		{"a[i + 1:n - 1:-1]", "(a[(i + 1):(n - 1):(-1)])"},
+		{"a[1:][0]", "((a[1:])[0])"},
+		{"a?.[1:]", "(a?.[1:])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestSliceExpression(t *testing.T) {
+	input := "myArray[1:n:2]"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.SliceExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.SliceExpression. got=%T", stmt.Expression)
+	}
+
+	testIdentifier(t, exp.Left, "myArray")
+	testIntegerLiteral(t, exp.Start, 1)
+	testIdentifier(t, exp.End, "n")
+	testIntegerLiteral(t, exp.Step, 2)
+}
+
+
+func TestPipeExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"xs |> sum", "sum(xs)"},
+		{"xs |> filter(even)", "filter(xs, even)"},
+		{"xs |> filter(even) |> map(double) |> sum", "sum(map(filter(xs, even), double))"},
+		{"sum(map(filter(xs, even), double))", "sum(map(filter(xs, even), double))"},
+		{"a + b |> f(c * d)", "f((a + b), (c * d))"},
+		{"x == y |> not", "not((x == y))"},
+		{"xs |> fn(x) { x }", "fn(x) x(xs)"},
+		{"xs |> mod.count()", "(mod.count)(xs)"},
+		{"xs |> f()()", "f()(xs)"},
+		{"total = xs |> sum", "total = sum(xs)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+
+func TestFunctionStatement(t *testing.T) {
+	input := "fn add(x, y) { x + y; }"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d",
+			len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.FunctionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.FunctionStatement. got=%T",
+			program.Statements[0])
+	}
+
+	testIdentifier(t, stmt.Name, "add")
+
+	if stmt.Function.Name != "add" {
+		t.Errorf("function literal name wrong. want=%q, got=%q", "add", stmt.Function.Name)
+	}
+
+	if len(stmt.Function.Parameters) != 2 {
+		t.Fatalf("function parameters wrong. want 2, got=%d", len(stmt.Function.Parameters))
+	}
+
+	expected := "fn add(x, y) (x + y)"
+	if program.String() != expected {
+		t.Errorf("expected=%q, got=%q", expected, program.String())
+	}
+}
+
+func TestLetFunctionLiteralName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let add = fn(x, y) { x + y };", "add"},
+		{"let double = x => x * 2;", "double"},
+		{"fn(x) { x };", ""},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		var function *ast.FunctionLiteral
+		switch stmt := program.Statements[0].(type) {
+		case *ast.LetStatement:
+			function = stmt.Value.(*ast.FunctionLiteral)
+		case *ast.ExpressionStatement:
+			function = stmt.Expression.(*ast.FunctionLiteral)
+		}
+
+		if function.Name != tt.expected {
+			t.Errorf("function name wrong. want=%q, got=%q", tt.expected, function.Name)
+		}
+	}
+}
+
+func TestArrowFunctionParsing(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedParams []string
+		expected       string
+	}{
+		{"x => x * 2", []string{"x"}, "fn(x) (x * 2)"},
+		{"(x) => x * 2", []string{"x"}, "fn(x) (x * 2)"},
+		{"(x, y) => x + y", []string{"x", "y"}, "fn(x, y) (x + y)"},
+		{"() => 1", []string{}, "fn() 1"},
+		{"(a, b) => { let c = a; c + b }", []string{"a", "b"}, "fn(a, b) let c = a;(c + b)"},
+		{"x => y => x + y", []string{"x"}, "fn(x) fn(y) (x + y)"},
+		{"x => x ? 1 : 2", []string{"x"}, "fn(x) (x ? 1 : 2)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function, ok := stmt.Expression.(*ast.FunctionLiteral)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.FunctionLiteral. got=%T", stmt.Expression)
+		}
+
+		if len(function.Parameters) != len(tt.expectedParams) {
+			t.Errorf("length parameters wrong. want %d, got=%d",
+				len(tt.expectedParams), len(function.Parameters))
+			continue
+		}
+
+		for i, ident := range tt.expectedParams {
+			testLiteralExpression(t, function.Parameters[i], ident)
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestArrowFunctionsInContext(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"map(xs, x => x * 2)", "map(xs, fn(x) (x * 2))"},
+		{"xs.reduce((acc, x) => acc + x, 0)", "(xs.reduce)(fn(acc, x) (acc + x), 0)"},
+		{"xs |> map(x => x + 1) |> sum", "sum(map(xs, fn(x) (x + 1)))"},
+		{"(a + b) * c", "((a + b) * c)"},
+		{"match (v) { x => x + 1 }", "match v { x => (x + 1) }"},
+		{"match (v) { x if ok => x }", "match v { x if ok => x }"},
+		{"match (v) { x if (ok) => x }", "match v { x if ok => x }"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestArrowFunctionParseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"(1, x) => x", "invalid arrow function parameter: 1"},
+		{"(x, y", "expected next token to be ), got y instead"},
+		{"(x,, y)", "no prefix parse function for , found"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+
+func TestRangeExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  string
+		inclusive bool
+	}{
+		{"0..10", "(0..10)", false},
+		{"0..=10", "(0..=10)", true},
+		{"0..10 step 2", "(0..10 step 2)", false},
+		{"10..=0 step -1", "(10..=0 step (-1))", true},
+		{"a + 1..b * 2", "((a + 1)..(b * 2))", false},
+		{"x in 0..n", "(x in (0..n))", false},
+		{"(0..n).len()", "((0..n).len)()", false},
+		{"let step = 2; 0..10 step step", "let step = 2;(0..10 step step)", false},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+
+		stmt := program.Statements[len(program.Statements)-1].(*ast.ExpressionStatement)
+		if rng, ok := stmt.Expression.(*ast.RangeExpression); ok && rng.Inclusive != tt.inclusive {
+			t.Errorf("range.Inclusive wrong for %q. want=%t, got=%t", tt.input, tt.inclusive, rng.Inclusive)
+		}
+	}
+}
+
+
+func TestMembershipExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a in b", "(a in b)"},
+		{"a not in b", "(a not in b)"},
+		{"a + 1 in b", "((a + 1) in b)"},
+		{"a in b == c not in d", "((a in b) == (c not in d))"},
+		{"!(a in b)", "(!(a in b))"},
+		{"k in h ? h[k] : 0", "((k in h) ? (h[k]) : 0)"},
+		{"let not = fn(x) { !x }; not(a in b)", "let not = fn(x) (!x);not((a in b))"},
+		{"not not in nots", "(not not in nots)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+
+func TestComparisonOperatorParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a <= b", "(a <= b)"},
+		{"a >= b", "(a >= b)"},
+		{"a + 1 <= b * 2", "((a + 1) <= (b * 2))"},
+		{"a <= b == b >= a", "((a <= b) == (b >= a))"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+
+func TestThrowStatement(t *testing.T) {
+	l := lexer.New("throw err;")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ThrowStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ThrowStatement. got=%T",
+			program.Statements[0])
+	}
+
+	if !testLiteralExpression(t, stmt.Value, "err") {
+		return
+	}
+
+	if program.String() != "throw err;" {
+		t.Errorf("expected=%q, got=%q", "throw err;", program.String())
+	}
+}
+
+func TestTryExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input      string
+		expected   string
+		catchParam string
+		hasCatch   bool
+		hasFinally bool
+	}{
+		{"try { f() } catch (e) { e }", "try f() catch (e) e", "e", true, false},
+		{"try { f() } catch { 0 }", "try f() catch 0", "", true, false},
+		{"try { f() } finally { g() }", "try f() finally g()", "", false, true},
+		{"try { f() } catch (err) { 0 } finally { g() }", "try f() catch (err) 0 finally g()", "err", true, true},
+		{"let x = try { f() } catch { 0 };", "let x = try f() catch 0;", "", true, false},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+
+		var exp ast.Expression
+		switch stmt := program.Statements[0].(type) {
+		case *ast.ExpressionStatement:
+			exp = stmt.Expression
+		case *ast.LetStatement:
+			exp = stmt.Value
+		}
+
+		try, ok := exp.(*ast.TryExpression)
+		if !ok {
+			t.Fatalf("exp is not ast.TryExpression. got=%T", exp)
+		}
+
+		if (try.Catch != nil) != tt.hasCatch || (try.Finally != nil) != tt.hasFinally {
+			t.Errorf("wrong blocks for %q. catch=%t, finally=%t", tt.input, try.Catch != nil, try.Finally != nil)
+		}
+
+		if tt.catchParam == "" {
+			if try.CatchParam != nil {
+				t.Errorf("try.CatchParam was not nil. got=%+v", try.CatchParam)
+			}
+		} else {
+			testIdentifier(t, try.CatchParam, tt.catchParam)
+		}
+	}
+}
+
+func TestTryExpressionParseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"try { 1 }", "try without catch or finally"},
+		{"try { 1 } catch (1) { 2 }", "expected next token to be IDENT, got ( instead"},
+		{"try 1 catch { 2 }", "expected next token to be {, got try instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+
+func TestPropagateExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"f()?", "(f()?)"},
+		{"f()?;", "(f()?)"},
+		{"let x = f(a)?;", "let x = (f(a)?);"},
+		{"a + f()?", "(a + (f()?))"},
+		{"-f()?", "(-(f()?))"},
+		{"g(f()?, 1)", "g((f()?), 1)"},
+		{"[f()?]", "[(f()?)]"},
+		{"f()?? 1", "(f() ?? 1)"},
+		{"a ? b : c", "(a ? b : c)"},
+		{"a ? f()? : c", "(a ? (f()?) : c)"},
+		{"fn() { f()? }", "fn() (f()?)"},
+		{"(f()?).x", "((f()?).x)"},
+		{"r? - 1", "((r?) - 1)"},
+		{"r? (x)", "(r?)(x)"},
+		{"r? [0]", "((r?)[0])"},
+		{"r ? -1 : 1", "(r ? (-1) : 1)"},
+		{"r ?(x) : y", "(r ? x : y)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+
+func TestImportStatement(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedPath  string
+		expectedAlias string
+		expectedNames []string
+		expected      string
+	}{
+		{`import "lib/strings.mk" as s;`, "lib/strings.mk", "s", nil, `import "lib/strings.mk" as s;`},
+		{`import { map, filter } from "lib/list.mk";`, "lib/list.mk", "", []string{"map", "filter"},
+			`import { map, filter } from "lib/list.mk";`},
+		{`import { one, } from "one.mk"`, "one.mk", "", []string{"one"}, `import { one } from "one.mk";`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ImportStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ImportStatement. got=%T",
+				program.Statements[0])
+		}
+
+		if stmt.Path != tt.expectedPath {
+			t.Errorf("stmt.Path wrong. want=%q, got=%q", tt.expectedPath, stmt.Path)
+		}
+
+		if tt.expectedAlias != "" {
+			testIdentifier(t, stmt.Alias, tt.expectedAlias)
+		} else if stmt.Alias != nil {
+			t.Errorf("stmt.Alias was not nil. got=%+v", stmt.Alias)
+		}
+
+		if len(stmt.Names) != len(tt.expectedNames) {
+			t.Fatalf("wrong number of names. want=%d, got=%d", len(tt.expectedNames), len(stmt.Names))
+		}
+		for i, name := range tt.expectedNames {
+			testIdentifier(t, stmt.Names[i], name)
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestExportStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"export let x = 5;", "export let x = 5;"},
+		{"export fn double(x) { x * 2 }", "export fn double(x) (x * 2)"},
+		{"export let [a, b] = pair;", "export let [a, b] = pair;"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if _, ok := program.Statements[0].(*ast.ExportStatement); !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExportStatement. got=%T",
+				program.Statements[0])
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestModuleParseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "a.mk";`, "expected next token to be as, got ; instead"},
+		{`import "a.mk" as 1;`, "expected next token to be IDENT, got as instead"},
+		{`import { a } "a.mk";`, `expected next token to be from, got a.mk instead`},
+		{`import {} from "a.mk";`, "import list is empty"},
+		{`import a from "a.mk";`, "expected next token to be STRING, got import instead"},
+		{"export 5;", "cannot export 5, only let and fn declarations"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+
+func TestParsingHashLiteralKeepsSourceOrder(t *testing.T) {
+	input := `{"b": 1, "a": 2, "c": 3 + 4}`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(hash.Keys) != len(hash.Pairs) {
+		t.Fatalf("hash.Keys has wrong length. got=%d", len(hash.Keys))
+	}
+	for i, want := range []string{"b", "a", "c"} {
+		if hash.Keys[i].String() != want {
+			t.Errorf("key %d wrong. want=%q, got=%q", i, want, hash.Keys[i].String())
+		}
+	}
+
+	if hash.String() != "{b:1, a:2, c:(3 + 4)}" {
+		t.Errorf("hash.String() wrong. got=%q", hash.String())
+	}
+}
+
+
+func TestTupleLiteralParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		length   int
+	}{
+		{"(1, 2)", "(1, 2)", 2},
+		{"(a + 1, b, [c])", "((a + 1), b, [c])", 3},
+		{"(x,)", "(x,)", 1},
+		{"()", "()", 0},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		tuple, ok := stmt.Expression.(*ast.TupleLiteral)
+		if !ok {
+			t.Fatalf("exp is not ast.TupleLiteral. got=%T", stmt.Expression)
+		}
+		if len(tuple.Elements) != tt.length {
+			t.Errorf("tuple.Elements has wrong length. want=%d, got=%d", tt.length, len(tuple.Elements))
+		}
+		if tuple.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, tuple.String())
+		}
+	}
+
+	// a parenthesised list followed by => is still an arrow function
+	l := lexer.New("(x, y) => x")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	if _, ok := stmt.Expression.(*ast.FunctionLiteral); !ok {
+		t.Errorf("exp is not ast.FunctionLiteral. got=%T", stmt.Expression)
+	}
+}
+
+
+func TestFloatLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"1.5;", 1.5},
+		{"0.25", 0.25},
+		{"10.0", 10},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.FloatLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %g. got=%g", tt.expected, literal.Value)
+		}
+	}
+
+	l := lexer.New("-0.5 * x + 1..2")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if program.String() != "((((-0.5) * x) + 1)..2)" {
+		t.Errorf("program.String() wrong. got=%q", program.String())
+	}
+}
+
 
 func testLetStatement(t *testing.T, s ast.Statement, name string) bool {
 	if s.TokenLiteral() != "let" {
@@ -8,6 +8,7 @@ import (
 	"monkey/lexer"
 	"monkey/parse"
 	"monkey/object"
+	"os"
 )
 
 const MONKEY_FACE = 
@@ -56,6 +57,37 @@ func Start(in io.Reader, out io.Writer) {
 	}
 }
 
+// RunFile evaluates the Monkey source file at path and returns the process
+// exit code: 0 on success, 1 if it fails to read, parse or evaluate.
+func RunFile(path string, out io.Writer) int {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(out, "%s\n", err)
+		return 1
+	}
+
+	l := lexer.New(string(source))
+	p := parse.New(l)
+
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParserErrors(out, p.Errors())
+		return 1
+	}
+
+	evaluator.ResetModules()
+	env := object.NewEnvironment()
+	env.SetFile(path)
+
+	evaluated := evaluator.Eval(program, env)
+	if evaluated != nil && evaluated.Type() == object.ERROR_OBJ {
+		io.WriteString(out, evaluated.Inspect())
+		io.WriteString(out, "\n")
+		return 1
+	}
+	return 0
+}
+
 func printParserErrors(out io.Writer, errors []string) {
 	io.WriteString(out, MONKEY_FACE)
 	io.WriteString(out, "Woops! We ran into some monkey business here!\n")
@@ -7,6 +7,7 @@ const (
 	// Identifiers + literals
 	IDENT = "IDENT" // add, foobar, x, y, ...
 	INT   = "INT"   // 123
+	FLOAT = "FLOAT" // 1.5
 	STRING = "STRING"
 
 	// Operators
@@ -17,12 +18,30 @@ const (
 	ASTERISK = "*"
 	SLASH    = "/"
 
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
+
 	LT = "<"
 	GT = ">"
 
+	LT_EQ = "<="
+	GT_EQ = ">="
+
 	EQ     = "=="
 	NOT_EQ = "!="
 
+	PIPE = "|>"
+
+	RANGE           = ".."
+	RANGE_INCLUSIVE = "..="
+
+	QUESTION       = "?"
+	PROPAGATE      = "PROPAGATE" // a ? touching its operand, as in result?
+	NULLISH        = "??"
+	OPTIONAL_CHAIN = "?."
+
 	// Delimiters
 	COMMA     = ","
 	SEMICOLON = ";"
@@ -34,6 +53,9 @@ const (
 	LBRACKET = "["
 	RBRACKET = "]"
 	COLON   = ":"
+	ARROW   = "=>"
+	ELLIPSIS = "..."
+	DOT      = "."
 
 	// Keywords
 	FUNCTION = "FUNCTION"
@@ -43,6 +65,18 @@ const (
 	RETURN   = "RETURN"
 	TRUE     = "TRUE"
 	FALSE    = "FALSE"
+	WHILE    = "WHILE"
+	FOR      = "FOR"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+	IN       = "IN"
+	MATCH    = "MATCH"
+	THROW    = "THROW"
+	TRY      = "TRY"
+	CATCH    = "CATCH"
+	FINALLY  = "FINALLY"
+	IMPORT   = "IMPORT"
+	EXPORT   = "EXPORT"
 )
 
 type TokenType string
@@ -60,6 +94,18 @@ var keywords = map[string]TokenType {
 	"return": RETURN,
 	"true": TRUE,
 	"false": FALSE,
+	"while": WHILE,
+	"for": FOR,
+	"break": BREAK,
+	"continue": CONTINUE,
+	"in": IN,
+	"match": MATCH,
+	"throw": THROW,
+	"try": TRY,
+	"catch": CATCH,
+	"finally": FINALLY,
+	"import": IMPORT,
+	"export": EXPORT,
 }
 
 func LookupIdent(ident string) TokenType {